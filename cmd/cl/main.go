@@ -1,10 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/BrianJHenry/chess/internal/chess"
 	"github.com/BrianJHenry/chess/internal/engine"
+	"github.com/BrianJHenry/chess/internal/pgn"
 )
 
 func main() {
@@ -16,6 +22,18 @@ func main() {
 		return
 	}
 
+	variant, quit := resolveVariant()
+
+	if quit {
+		return
+	}
+
+	isChess960, quit := resolveChess960()
+
+	if quit {
+		return
+	}
+
 	userColor, quit := resolveUserColor()
 
 	if quit {
@@ -36,70 +54,134 @@ func main() {
 		return
 	}
 
-	game := chess.InitialiseGame()
-	for !game.Checkmate && !game.Stalemate {
+	state := chess.InitialiseState()
+	if isChess960 {
+		positionNumber := rand.Intn(960)
+		state, err = chess.InitialiseChess960State(positionNumber)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("Chess960 position %d\n", positionNumber)
+	}
+	state.SetVariant(variant)
+
+	game, err := chess.InitialiseGameFromState(state)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	// The players are named for the PGN tags if the game is saved
+	white, black := "Player", "Player"
+	if isUseEngine && userColor == chess.White {
+		black = "Engine " + engineVersion
+	} else if isUseEngine {
+		white = "Engine " + engineVersion
+	}
+	tags := []pgn.Tag{
+		{Name: "Event", Value: "Casual game"},
+		{Name: "Date", Value: time.Now().Format("2006.01.02")},
+		{Name: "White", Value: white},
+		{Name: "Black", Value: black},
+	}
+	if isChess960 && variant == chess.Standard {
+		// The starting position may have the kings and rooks on their standard files, so the game is only known to be Chess960 by its tag
+		tags = append(tags, pgn.Tag{Name: "Variant", Value: "Chess960"})
+	}
+
+	for !game.Outcome.IsOver() {
 		fmt.Println(chess.BoardToDisplayString(game.State.Board))
+		if variant == chess.ThreeCheck {
+			fmt.Printf("Checks given: white %d; black %d\n", game.State.Checks.White, game.State.Checks.Black)
+		}
+		if variant == chess.Crazyhouse {
+			fmt.Printf("Pockets: [%s]\n", game.State.Pockets)
+		}
 
-		var move chess.Move
 		if game.State.ActiveColor == userColor {
-			move, quit = getUserMove(game.State, game.PossibleMoves)
-			if quit {
+			if quit = doUserMove(&game); quit {
+				resolveSaveGame(game, tags)
 				return
 			}
-
 		} else {
-			move = engine.ChooseMove(game.State, game.PossibleMoves)
-		}
-
-		err = game.DoMove(move)
-		if err != nil {
-			fmt.Println(err.Error())
-			return
+			err = game.DoMove(engine.ChooseMove(game.State, game.PossibleMoves))
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
 		}
 	}
 
-	if game.Stalemate {
-		fmt.Println("Stalemate")
-	} else if game.Checkmate {
-		if game.State.ActiveColor == chess.White {
-			fmt.Println("Black wins!")
-		} else {
-			fmt.Println("White wins!")
-		}
+	fmt.Println(chess.BoardToDisplayString(game.State.Board))
+	switch game.Outcome.Result {
+	case chess.WhiteWins:
+		fmt.Printf("White wins by %s!\n", game.Outcome.Termination)
+	case chess.BlackWins:
+		fmt.Printf("Black wins by %s!\n", game.Outcome.Termination)
+	case chess.Draw:
+		fmt.Printf("Draw by %s\n", game.Outcome.Termination)
 	}
 
+	resolveSaveGame(game, tags)
+
 	fmt.Scan()
 }
 
-func getUserMove(state chess.State, possibleMoves []chess.Move) (move chess.Move, quit bool) {
+// doUserMove prompts for moves in algebraic or coordinate notation until a legal one is made.
+func doUserMove(game *chess.Game) (quit bool) {
 	for {
 		var userMove string
 		fmt.Print("Input move: ")
 		fmt.Scanln(&userMove)
 
 		if userMove == "q" || userMove == "Q" {
-			return chess.Move{}, true
+			return true
 		}
 
-		move, err := chess.AlgebraicNotation(userMove).ToMove(state)
+		// Input which is not in coordinate notation, such as Nf3, is read as algebraic notation
+		err := game.DoUCIMove(chess.UCINotation(userMove))
+		if errors.Is(err, chess.ErrMalformedMove) {
+			err = game.DoAlgebraicMove(chess.AlgebraicNotation(userMove))
+		}
 		if err != nil {
 			fmt.Println(err.Error())
 			continue
 		}
 
-		isMoveLegal := false
-		for _, legalMove := range possibleMoves {
-			if move == legalMove {
-				isMoveLegal = true
-			}
+		return false
+	}
+}
+
+// resolveSaveGame prompts for a file to append the game to in PGN, until it is saved or the user declines.
+func resolveSaveGame(game chess.Game, tags []pgn.Tag) {
+	for {
+		var fileName string
+		fmt.Print("Save game as PGN? (file name or n) ")
+		// A blank line or the end of the input declines to save, rather than prompting again forever
+		if _, err := fmt.Scanln(&fileName); err != nil {
+			return
+		}
+		if fileName == "n" || fileName == "N" || fileName == "q" || fileName == "Q" {
+			return
 		}
 
-		if !isMoveLegal {
-			fmt.Println("Illegal move.")
+		file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Println(err.Error())
+			continue
+		}
+		err = pgn.Write(file, pgn.FromGame(game, tags))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Println(err.Error())
 			continue
 		}
 
-		return move, false
+		fmt.Printf("Saved to %s\n", fileName)
+		return
 	}
 }
 
@@ -119,6 +201,49 @@ func resolveUserColor() (color chess.Color, quit bool) {
 	}
 }
 
+func resolveVariant() (variant chess.Variant, quit bool) {
+	for {
+		var variantName string
+		fmt.Print("Select variant: (o for options) ")
+		fmt.Scanln(&variantName)
+
+		if variantName == "o" {
+			// Names are printed without spaces, which ParseVariant ignores, as input is read a word at a time
+			fmt.Println("Available variants")
+			for _, variant := range chess.Variants {
+				fmt.Println(strings.ReplaceAll(strings.ToLower(variant.Name()), " ", ""))
+			}
+			continue
+		} else if variantName == "q" || variantName == "Q" {
+			return nil, true
+		}
+
+		variant, err := chess.ParseVariant(variantName)
+		if err != nil {
+			fmt.Println(err.Error())
+			continue
+		}
+
+		return variant, false
+	}
+}
+
+func resolveChess960() (isChess960 bool, quit bool) {
+	for {
+		var chess960 string
+		fmt.Print("Play Chess960? (y/n) ")
+		fmt.Scanln(&chess960)
+
+		if chess960 == "y" || chess960 == "Y" {
+			return true, false
+		} else if chess960 == "n" || chess960 == "N" {
+			return false, false
+		} else if chess960 == "q" || chess960 == "Q" {
+			return false, true
+		}
+	}
+}
+
 func resolveUseEngine() (isUseEngine bool, quit bool) {
 
 	for {
@@ -1,7 +1,115 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+	"github.com/BrianJHenry/chess/internal/perft"
+)
+
+const startingFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 
 func main() {
-	fmt.Println("hello engine")
+	if len(os.Args) < 2 {
+		fmt.Println("usage: engine perft [flags]")
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "perft":
+		if err := runPerft(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("unknown command %s\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+// runPerft counts the leaf nodes of the move tree of a position, or of every position in a suite file.
+func runPerft(args []string) error {
+	flags := flag.NewFlagSet("perft", flag.ExitOnError)
+	fen := flags.String("fen", startingFEN, "position to count from")
+	variantName := flags.String("variant", "standard", "variant the position is played under")
+	depth := flags.Int("depth", 5, "depth of the move tree")
+	goroutines := flags.Int("goroutines", 1, "number of goroutines to share the root moves between")
+	hashEntries := flags.Int("hash", 0, "number of subtree counts to keep in a hash table, or 0 for no table")
+	suite := flags.String("suite", "", "file of FEN;depth;expected rows to check instead of a single position")
+	flags.Parse(args)
+
+	options := perft.Options{Goroutines: *goroutines, HashEntries: *hashEntries}
+	if *suite != "" {
+		return runPerftSuite(*suite, options)
+	}
+
+	variant, err := chess.ParseVariant(*variantName)
+	if err != nil {
+		return err
+	}
+	// The starting position is adapted to the variant, such as by removing the castling rights in Antichess
+	state := chess.InitialiseState()
+	state.SetVariant(variant)
+	if *fen != startingFEN {
+		state, err = chess.ParseVariantFEN(*fen, variant)
+		if err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	results, err := perft.Divide(state, *depth, options)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	total := 0
+	for _, result := range results {
+		fmt.Printf("%s: %d\n", result.Move.ToUCINotation(false), result.Nodes)
+		total += result.Nodes
+	}
+
+	fmt.Printf("\nNodes searched: %d\n", total)
+	fmt.Printf("Time: %s (%.0f nodes/s)\n", elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	return nil
+}
+
+// runPerftSuite checks the node count of every case in a suite file, returning an error if any are incorrect.
+func runPerftSuite(path string, options perft.Options) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	cases, err := perft.ReadSuite(file)
+	if err != nil {
+		return err
+	}
+
+	failures := 0
+	for _, suiteCase := range cases {
+		start := time.Now()
+		nodes, err := perft.Count(suiteCase.State, suiteCase.Depth, options)
+		if err != nil {
+			return err
+		}
+
+		status := "ok"
+		if nodes != suiteCase.Expected {
+			status = fmt.Sprintf("FAIL (expected %d)", suiteCase.Expected)
+			failures++
+		}
+		fmt.Printf("%s depth %d: %d %s in %s\n", suiteCase.FEN, suiteCase.Depth, nodes, status, time.Since(start).Round(time.Millisecond))
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d cases failed", failures, len(cases))
+	}
+	fmt.Printf("all %d cases passed\n", len(cases))
+	return nil
 }
@@ -3,7 +3,7 @@ package chess
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	"strings"
 )
 
 // AlgebraicNotation represents the standard written notation for a chess move.
@@ -17,6 +17,12 @@ func (move Move) ToAlgebraicNotation(state State) (AlgebraicNotation, error) {
 		baseString = "O-O"
 	case QueenSideCastle:
 		baseString = "O-O-O"
+	case Drop:
+		end, err := positionToString(move.End)
+		if err != nil {
+			return "", err
+		}
+		baseString = string(pieceToFENPiece(getWhitePiece(move.Piece))) + "@" + end
 	default:
 		var err error
 		baseString, err = move.getAlgebraicNotationCore(state.Board)
@@ -25,9 +31,9 @@ func (move Move) ToAlgebraicNotation(state State) (AlgebraicNotation, error) {
 		}
 	}
 
-	// Check and mate
+	// Check and mate, where exploding the enemy king in Atomic counts as mate as the exploded king is in check with no moves
 	state.DoMove(move)
-	isInCheck, err := state.Board.isInCheck(state.ActiveColor)
+	isInCheck, err := state.IsInCheck(state.ActiveColor)
 	if err != nil {
 		return AlgebraicNotation(baseString), err
 	}
@@ -48,133 +54,158 @@ func (move Move) ToAlgebraicNotation(state State) (AlgebraicNotation, error) {
 }
 
 // ToMove converts from standard algebraic notation to a move struct.
+// The notation is matched against the legal moves in the state, so an error wrapping ErrMalformedMove, ErrIllegalMove, ErrAmbiguousMove or ErrWrongSide is returned if it does not describe exactly one of them.
 func (algebraicNotation AlgebraicNotation) ToMove(state State) (Move, error) {
-	// Castling
-	if algebraicNotation == "O-O" || algebraicNotation == "O-O+" || algebraicNotation == "O-O#" {
-		var rank int8
-		if state.ActiveColor == Black {
-			rank = 0
-		} else {
-			rank = 7
-		}
-		return Move{
-			Start: Position{rank, 4},
-			End:   Position{rank, 6},
-			Flag:  KingSideCastle,
-		}, nil
-	} else if algebraicNotation == "O-O-O" || algebraicNotation == "O-O-O+" || algebraicNotation == "O-O-O#" {
-		var rank int8
-		if state.ActiveColor == Black {
-			rank = 0
-		} else {
-			rank = 7
-		}
-		return Move{
-			Start: Position{rank, 4},
-			End:   Position{rank, 2},
-			Flag:  QueenSideCastle,
-		}, nil
+	legalMoves, err := state.GenerateAllMoves()
+	if err != nil {
+		return Move{}, err
 	}
 
-	length := len(algebraicNotation)
-	lastChar := algebraicNotation[length-1]
-	if lastChar == '#' || lastChar == '+' {
-		length--
+	return algebraicNotation.resolve(state, legalMoves)
+}
+
+// algebraicMove holds the parts of a move given in algebraic notation.
+// The hint fields are -1 when they are not given.
+type algebraicMove struct {
+	castle       bool
+	kingSide     bool
+	drop         bool
+	piece        Piece
+	end          Position
+	hintX, hintY int8
+	promotion    MoveFlag
+}
+
+// resolve returns the single legal move described by the notation.
+func (algebraicNotation AlgebraicNotation) resolve(state State, legalMoves []Move) (Move, error) {
+	parsed, ok := algebraicNotation.parse()
+	if !ok {
+		return Move{}, &MoveError{string(algebraicNotation), ErrMalformedMove}
 	}
 
-	var flag MoveFlag = None
-	if algebraicNotation[length-2] == '=' {
-		switch algebraicNotation[length-1] {
-		case 'Q':
-			flag = PromoteToQueen
-		case 'R':
-			flag = PromoteToRook
-		case 'B':
-			flag = PromoteToBishop
-		case 'N':
-			flag = PromoteToKnight
+	move, matches := parsed.match(state, legalMoves)
+	switch {
+	case matches == 1:
+		return move, nil
+	case matches > 1:
+		return Move{}, &MoveError{string(algebraicNotation), ErrAmbiguousMove}
+	}
+
+	// Check whether the move would be legal for the side which is not to move
+	otherSide := state
+	otherSide.ActiveColor = !state.ActiveColor
+	otherSide.EnPassantPosition = PositionOpt{Ok: false}
+	if otherMoves, err := otherSide.GenerateAllMoves(); err == nil {
+		if _, matches := parsed.match(otherSide, otherMoves); matches > 0 {
+			return Move{}, &MoveError{string(algebraicNotation), ErrWrongSide}
 		}
-		length -= 2
 	}
 
-	an := string(algebraicNotation)
-	endPosition, err := stringToPosition(an[length-2 : length])
-	if err != nil {
-		return Move{}, err
+	return Move{}, &MoveError{string(algebraicNotation), ErrIllegalMove}
+}
+
+// parse splits the notation into its parts, accepting 0 in place of O for castling, an optional = before the promotion piece, and any trailing check, mate or annotation symbols.
+// Drops are given as the piece, an @ and the square, such as N@f3, where the P may be left out for a pawn.
+func (algebraicNotation AlgebraicNotation) parse() (algebraicMove, bool) {
+	notation := strings.TrimRight(strings.TrimSpace(string(algebraicNotation)), "+#!?")
+	parsed := algebraicMove{hintX: -1, hintY: -1}
+
+	switch notation {
+	case "O-O", "0-0":
+		parsed.castle, parsed.kingSide = true, true
+		return parsed, true
+	case "O-O-O", "0-0-0":
+		parsed.castle = true
+		return parsed, true
 	}
-	length -= 2
 
-	if length == 0 {
-		piece := getPiece('P', state.ActiveColor)
-		start, err := findStartPosition(piece, state, endPosition, -1, -1)
-		if err != nil {
-			return Move{}, err
+	// Drop
+	if at := strings.IndexByte(notation, '@'); at >= 0 {
+		parsed.drop = true
+		parsed.piece = WhitePawn
+		if at == 1 && strings.IndexByte("PNBRQ", notation[0]) >= 0 {
+			parsed.piece = fenPieceToWhitePiece(notation[0])
+		} else if at != 0 {
+			return parsed, false
 		}
-		return Move{
-			start,
-			endPosition,
-			None,
-			EmptySquare,
-		}, nil
+
+		end, err := stringToPosition(notation[at+1:])
+		parsed.end = end
+		return parsed, err == nil
 	}
 
-	isCapture := false
-	if algebraicNotation[length-1] == 'x' {
-		isCapture = true
-		length--
+	// Piece
+	parsed.piece = WhitePawn
+	if len(notation) > 0 && strings.IndexByte("KQRBN", notation[0]) >= 0 {
+		parsed.piece = fenPieceToWhitePiece(notation[0])
+		notation = notation[1:]
 	}
 
-	piece := getPiece(algebraicNotation[0], state.ActiveColor)
-	var start Position
-	if length == 1 && piece != WhitePawn && piece != BlackPawn {
-		start, err = findStartPosition(piece, state, endPosition, -1, -1)
-		if err != nil {
-			return Move{}, err
-		}
-	} else if length == 1 {
-		file, err := runeToFile(rune(algebraicNotation[0]))
-		if err != nil {
-			return Move{}, err
+	// Promotion
+	if len(notation) > 0 && strings.IndexByte("QRBNK", notation[len(notation)-1]) >= 0 {
+		if parsed.piece != WhitePawn {
+			return parsed, false
 		}
+		parsed.promotion = promotionFlag(notation[len(notation)-1])
+		notation = strings.TrimSuffix(notation[:len(notation)-1], "=")
+	}
 
-		start, err = findStartPosition(piece, state, endPosition, -1, file)
-		if err != nil {
-			return Move{}, err
-		}
-	} else if length == 2 {
-		if rank, err := strconv.Atoi(string(algebraicNotation[1])); err == nil {
-			start, err = findStartPosition(piece, state, endPosition, int8(rank), -1)
-			if err != nil {
-				return Move{}, err
-			}
-		} else {
-			file, err := runeToFile(rune(algebraicNotation[1]))
-			if err != nil {
-				return Move{}, err
-			}
-			start, err = findStartPosition(piece, state, endPosition, -1, file)
-			if err != nil {
-				return Move{}, err
+	// End position
+	if len(notation) < 2 {
+		return parsed, false
+	}
+	end, err := stringToPosition(notation[len(notation)-2:])
+	if err != nil {
+		return parsed, false
+	}
+	parsed.end = end
+	notation = strings.TrimSuffix(notation[:len(notation)-2], "x")
+
+	// Disambiguation by file and rank
+	if len(notation) > 0 && notation[0] >= 'a' && notation[0] <= 'h' {
+		parsed.hintY = int8(notation[0] - 'a')
+		notation = notation[1:]
+	}
+	if len(notation) > 0 && notation[0] >= '1' && notation[0] <= '8' {
+		parsed.hintX = int8(7 - (notation[0] - '1'))
+		notation = notation[1:]
+	}
+
+	return parsed, notation == ""
+}
+
+// match returns the last of the legal moves matching the parsed notation, along with the number of matches.
+// A pawn move to the last rank without a promotion piece matches every promotion, so it is ambiguous.
+func (parsed algebraicMove) match(state State, legalMoves []Move) (move Move, matches int) {
+	piece := colorPiece(parsed.piece, state.ActiveColor)
+	for _, legalMove := range legalMoves {
+		if parsed.castle {
+			if (legalMove.Flag == KingSideCastle && parsed.kingSide) || (legalMove.Flag == QueenSideCastle && !parsed.kingSide) {
+				move, matches = legalMove, matches+1
 			}
+			continue
 		}
-	} else {
-		// Case where the disambiguating gives the full position
-		start, err = stringToPosition(string(algebraicNotation)[1:2])
-		if err != nil {
-			return Move{}, err
+
+		if parsed.drop {
+			if legalMove.IsDrop() && legalMove.Piece == piece && legalMove.End == parsed.end {
+				move, matches = legalMove, matches+1
+			}
+			continue
 		}
-	}
 
-	// Check for en passant
-	if isCapture && (piece == WhitePawn || piece == BlackPawn) && state.Board.GetSquare(endPosition) == EmptySquare {
-		flag = EnPassant
+		if legalMove.IsCastle() ||
+			state.Board.GetSquare(legalMove.Start) != piece ||
+			legalMove.End != parsed.end ||
+			(parsed.hintX != -1 && legalMove.Start.X != parsed.hintX) ||
+			(parsed.hintY != -1 && legalMove.Start.Y != parsed.hintY) ||
+			(parsed.promotion != None && legalMove.Flag != parsed.promotion) {
+
+			continue
+		}
+		move, matches = legalMove, matches+1
 	}
 
-	return Move{
-		Start: start,
-		End:   endPosition,
-		Flag:  flag,
-	}, nil
+	return
 }
 
 // getAlgebraicNotationCore generates the core notation for non-castling moves.
@@ -208,6 +239,8 @@ func (move Move) getAlgebraicNotationCore(board Board) (string, error) {
 			base += "=B"
 		case PromoteToKnight:
 			base += "=N"
+		case PromoteToKing:
+			base += "=K"
 		}
 
 		return base, nil
@@ -265,17 +298,13 @@ func (move Move) getAlgebraicNotationCore(board Board) (string, error) {
 	case WhiteKing, BlackKing:
 		base := "K"
 
-		// Captures
-		if board.GetSquare(move.End) != EmptySquare {
-			base += "x"
-		}
-
-		// End position
-		endPosition, err := positionToString(move.End)
+		// Disambiguate and get core, as promotions in Antichess can leave several kings
+		visiblePositions := kingAttacks[squareOf(move.End)].Positions()
+		core, err := getPositionalAlgebraicNotation(board, piece, move, PositionsToOptionalPositions(visiblePositions))
 		if err != nil {
 			return "", err
 		}
-		base += endPosition
+		base += core
 
 		return base, nil
 	}
@@ -337,135 +366,30 @@ func getPositionalAlgebraicNotation(board Board, piece Piece, move Move, visible
 	return base, nil
 }
 
-// findStartPosition parses move.Start from a move in algebraic notation.
-func findStartPosition(piece Piece, state State, end Position, hintX, hintY int8) (Position, error) {
-	switch piece {
-	case WhitePawn, BlackPawn:
-		var searchDirection int8
-		if piece == WhitePawn {
-			searchDirection = 1
-		} else {
-			searchDirection = -1
-		}
-
-		// Capture
-		if hintY != -1 {
-			return Position{end.X + searchDirection, hintY}, nil
-		}
-
-		// Non-Capture
-		oneSquare := Position{end.X + searchDirection, end.Y}
-		if state.Board.GetSquare(oneSquare) == piece {
-			return oneSquare, nil
-		} else {
-			return Position{end.X + (2 * searchDirection), end.Y}, nil
-		}
-	case WhiteRook, BlackRook:
-		rookVisions := state.Board.getDirectionalVision(end, rookDirections)
-		start, err := findStartPositionForVisions(piece, state.Board, hintX, hintY, rookVisions[:])
-		if err != nil {
-			return Position{}, err
-		}
-		return start, nil
-	case WhiteKnight, BlackKnight:
-		knighVisions := getKnightVision(end)
-		start, err := findStartPositionForVisions(piece, state.Board, hintX, hintY, PositionsToOptionalPositions(knighVisions))
-		if err != nil {
-			return Position{}, err
-		}
-		return start, nil
-	case WhiteBishop, BlackBishop:
-		bishopVisions := state.Board.getDirectionalVision(end, bishopDirections)
-		start, err := findStartPositionForVisions(piece, state.Board, hintX, hintY, bishopVisions[:])
-		if err != nil {
-			return Position{}, err
-		}
-		return start, nil
-	case WhiteQueen, BlackQueen:
-		rookVisions := state.Board.getDirectionalVision(end, rookDirections)
-		bishopVisions := state.Board.getDirectionalVision(end, bishopDirections)
-		visions := rookVisions[:]
-		visions = append(visions, bishopVisions[:]...)
-		start, err := findStartPositionForVisions(piece, state.Board, hintX, hintY, visions)
-		if err != nil {
-			return Position{}, err
-		}
-		return start, nil
-	case WhiteKing, BlackKing:
-		start, err := state.Board.findKing(state.ActiveColor)
-		if err != nil {
-			return Position{}, err
-		}
-		return start, nil
-	}
-	return Position{}, errors.New("invalid piece")
-}
-
-// findStartPositionForVisions retrieves move.Start based on the possible moves of the moved piece type.
-func findStartPositionForVisions(piece Piece, board Board, hintX, hintY int8, visions []PositionOpt) (Position, error) {
-	for _, vision := range visions {
-		// Skip out of bounds
-		if !vision.Ok {
-			continue
-		}
-
-		// TODO: improve this ugliness!
-		if hintX != -1 {
-			if vision.Position.X == hintX && board.GetSquare(vision.Position) == piece {
-				return vision.Position, nil
-			}
-		} else if hintY != -1 {
-			if vision.Position.Y == hintY && board.GetSquare(vision.Position) == piece {
-				return vision.Position, nil
-			}
-		} else if board.GetSquare(vision.Position) == piece {
-			return vision.Position, nil
-		}
+// fenPieceToWhitePiece returns the white piece for an upper case piece letter.
+func fenPieceToWhitePiece(letter byte) Piece {
+	piece, err := fenPieceToPiece(rune(letter))
+	if err != nil {
+		return EmptySquare
 	}
-
-	return Position{}, errors.New("given the constraints, the starting position could not be found")
+	return piece
 }
 
-// getPiece retrieves the Piece type based on the turn and first character in the algebraic notation version of the move.
-func getPiece(piece byte, turn Color) Piece {
-	switch piece {
-	case 'K':
-		if turn == Black {
-			return BlackKing
-		} else {
-			return WhiteKing
-		}
+// promotionFlag returns the flag for promoting to the piece with the given upper case letter.
+func promotionFlag(letter byte) MoveFlag {
+	switch letter {
 	case 'Q':
-		if turn == Black {
-			return BlackQueen
-		} else {
-			return WhiteQueen
-		}
+		return PromoteToQueen
 	case 'R':
-		if turn == Black {
-			return BlackRook
-		} else {
-			return WhiteRook
-		}
+		return PromoteToRook
 	case 'B':
-		if turn == Black {
-			return BlackBishop
-		} else {
-			return WhiteBishop
-		}
+		return PromoteToBishop
 	case 'N':
-		if turn == Black {
-			return BlackKnight
-		} else {
-			return WhiteKnight
-		}
-	default: // pawn
-		if turn == Black {
-			return BlackPawn
-		} else {
-			return WhitePawn
-		}
+		return PromoteToKnight
+	case 'K':
+		return PromoteToKing
 	}
+	return None
 }
 
 // stringToPosition takes in a 2 character letter number combo to specify the file and rank of a position. Ex. A1 -> 7, 0; C4 => 4, 2.
@@ -0,0 +1,53 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAlgebraicPromotion(t *testing.T) {
+	tests := []struct {
+		fen       string
+		notation  AlgebraicNotation
+		flag      MoveFlag
+		canonical AlgebraicNotation
+	}{
+		{"1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=Q", PromoteToQueen, "a8=Q"},
+		{"1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8N", PromoteToKnight, "a8=N"},
+		{"1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1", "axb8=R+", PromoteToRook, "axb8=R+"},
+		{"4k3/8/8/8/8/8/7p/4K3 b - - 0 1", "h1=B", PromoteToBishop, "h1=B"},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		move, err := test.notation.ToMove(state)
+		if err != nil {
+			t.Errorf("%s in %s: %v", test.notation, test.fen, err)
+			continue
+		}
+		if move.Flag != test.flag {
+			t.Errorf("incorrect flag for %s: expected=%d; actual=%d", test.notation, test.flag, move.Flag)
+		}
+
+		algebraic, err := move.ToAlgebraicNotation(state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if algebraic != test.canonical {
+			t.Errorf("incorrect notation for %s: expected=%s; actual=%s", test.notation, test.canonical, algebraic)
+		}
+	}
+
+	// A pawn reaching the last rank must say what it promotes to, as every promotion matches otherwise
+	state, err := ParseFEN("1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if _, err := AlgebraicNotation("a8").ToMove(state); !errors.Is(err, ErrAmbiguousMove) {
+		t.Errorf("expected an ambiguous move error for a promotion without a piece: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package chess
+
+// generateAntichessMoves replaces the contents of the list with the legal moves of the given stage under the rules of Antichess.
+// There is no check, so every move of a piece is legal unless a capture is available, in which case only captures are legal.
+// Pieces in the active color's pocket are dropped as in Crazyhouse if drops are allowed.
+func (state *State) generateAntichessMoves(moves *MoveList, stage MoveStage, drops bool) error {
+	moves.Clear()
+
+	// No move can give check without a royal king
+	if stage == CheckingMoves {
+		return nil
+	}
+
+	board := &state.Board
+	generator := moveGenerator{
+		state:     state,
+		stage:     AllMoves,
+		own:       board.ColorPieces(state.ActiveColor),
+		enemy:     board.ColorPieces(!state.ActiveColor),
+		occupied:  board.Occupied(),
+		checkMask: allSquares,
+		antichess: true,
+	}
+	generator.appendMoves(moves)
+	if drops {
+		generator.appendDrops(moves)
+	}
+
+	// Captures are compulsory, so every move is generated to find out whether there are any before splitting them into stages
+	for _, move := range moves.Moves() {
+		if move.ToMove().IsCapture() {
+			moves.filter(Move.IsCapture)
+			break
+		}
+	}
+
+	switch stage {
+	case CaptureMoves:
+		moves.filter(func(move Move) bool {
+			return move.IsCapture() || move.IsPromotion()
+		})
+	case QuietMoves:
+		moves.filter(func(move Move) bool {
+			return !move.IsCapture() && !move.IsPromotion()
+		})
+	}
+	return nil
+}
+
+type antichessVariant struct {
+	standardVariant
+}
+
+func (antichessVariant) Name() string {
+	return "Antichess"
+}
+
+// Outcome returns a win for the side to move if it has no pieces left or no legal moves.
+func (antichessVariant) Outcome(state *State, legalMoves []Move) Outcome {
+	if state.Board.ColorPieces(state.ActiveColor) == 0 {
+		return winFor(state.ActiveColor, AllPiecesLost)
+	}
+	if len(legalMoves) == 0 {
+		return winFor(state.ActiveColor, Stalemate)
+	}
+	return Outcome{Ongoing, Unterminated}
+}
+
+// HasInsufficientMaterialToWin returns whether each side has only bishops, all on squares of a different color to the other side's,
+// as then neither side can ever be forced to capture and neither can lose its pieces.
+func (antichessVariant) HasInsufficientMaterialToWin(state *State, color Color) bool {
+	white, black := state.Board.ColorPieces(White), state.Board.ColorPieces(Black)
+	if white == 0 || black == 0 || white != state.Board.Pieces(WhiteBishop) || black != state.Board.Pieces(BlackBishop) {
+		return false
+	}
+	return (white&lightSquares == 0 && black&^lightSquares == 0) || (white&^lightSquares == 0 && black&lightSquares == 0)
+}
+
+func (antichessVariant) Rules() Rules {
+	return Rules{OrdinaryKing: true}
+}
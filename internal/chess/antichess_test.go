@@ -0,0 +1,140 @@
+package chess
+
+import "testing"
+
+func TestAntichessMoveCount(t *testing.T) {
+	state, err := ParseVariantFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1", Antichess)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for depth, expected := range []int{20, 400, 8067, 153299, 2732672} {
+		testMoveCount(t, state, expected, depth+1)
+	}
+}
+
+func TestCapturesAreCompulsory(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected int
+	}{
+		// Only the pawn capture is legal while it is available
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", 1},
+		// The king can capture, even onto an attacked square
+		{"8/8/8/8/8/1p6/1r6/K7 w - - 0 1", 1},
+		// Pawns may promote to a king
+		{"8/P7/8/8/8/8/8/7k w - - 0 1", 5},
+		// A capture by promotion counts as a capture
+		{"1n6/P7/8/8/8/8/8/7k w - - 0 1", 5},
+		// En passant is compulsory too
+		{"8/8/8/3pP3/8/8/8/7k w - d6 0 1", 1},
+	}
+
+	for _, test := range tests {
+		state, err := ParseVariantFEN(test.fen, Antichess)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		moves, err := state.GenerateAllMoves()
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if len(moves) != test.expected {
+			t.Errorf("incorrect number of moves for %s: expected=%d; actual=%d", test.fen, test.expected, len(moves))
+		}
+	}
+}
+
+func TestAntichessOutcomes(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     AlgebraicNotation
+		expected Outcome
+	}{
+		// Losing the last piece wins
+		{"8/8/8/8/8/8/1p6/R7 b - - 0 1", "bxa1=Q", Outcome{WhiteWins, AllPiecesLost}},
+		// Being stalemated wins
+		{"8/8/8/8/8/p7/P7/7R w - - 0 1", "Rh2", Outcome{BlackWins, Stalemate}},
+		// Bishops on squares of different colors can never capture each other
+		{"8/8/8/8/1b6/7n/8/5B2 w - - 0 1", "Bxh3", Outcome{Draw, InsufficientMaterial}},
+	}
+
+	for _, test := range tests {
+		state, err := ParseVariantFEN(test.fen, Antichess)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		game, err := InitialiseGameFromState(state)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		if err := game.DoAlgebraicMove(test.move); err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if game.Outcome != test.expected {
+			t.Errorf("incorrect outcome after %s in %s: expected=%s; actual=%s", test.move, test.fen, test.expected, game.Outcome)
+		}
+	}
+}
+
+func TestAntichessKings(t *testing.T) {
+	state, err := ParseVariantFEN("8/1P6/8/8/8/8/8/K1k5 w - - 0 1", Antichess)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if problems := state.Validate(); len(problems) != 0 {
+		t.Errorf("unexpected problems with a king next to the enemy king: %v", problems)
+	}
+
+	move, err := AlgebraicNotation("b8=K").ToMove(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if move.Flag != PromoteToKing || move.String() != "b7b8k" {
+		t.Errorf("incorrect promotion to a king: %+v", move)
+	}
+
+	// The new king is told apart from the old one by its file, and kings are never in check
+	doStateAlgebraicMove(t, &state, "b8=K")
+	doStateAlgebraicMove(t, &state, "Kd2")
+	move, err = AlgebraicNotation("Kbb7").ToMove(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	algebraic, err := move.ToAlgebraicNotation(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if algebraic != "Kb7" {
+		t.Errorf("incorrect notation for a king move: %s", algebraic)
+	}
+	if problems := state.Validate(); len(problems) != 0 {
+		t.Errorf("unexpected problems with two kings: %v", problems)
+	}
+
+	if _, err := ParseVariantFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", Antichess); err == nil {
+		t.Errorf("expected castling rights to be rejected in Antichess")
+	}
+
+	state = InitialiseState()
+	state.SetVariant(Antichess)
+	if state.FEN() != "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1" {
+		t.Errorf("castling rights were not removed: %s", state.FEN())
+	}
+}
+
+func TestAntichessHashAndStages(t *testing.T) {
+	state, err := ParseVariantFEN("rnbqkbnr/ppp2ppp/8/3pp3/4P3/5N2/PPPP1PPP/RNBQKB1R w - - 0 3", Antichess)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	testHashAfterMoves(t, state, 3)
+	testStagedMoveGeneration(t, state, 2)
+}
@@ -0,0 +1,145 @@
+package chess
+
+// Explosion records the pieces removed from the squares around a capture in Atomic, so that the capture can be undone.
+// The capturing and captured pieces are not included, as they are given by the move.
+type Explosion struct {
+	// Squares holds the squares next to the capture whose pieces exploded.
+	Squares Bitboard
+	// Pieces holds the exploded pieces in the order of their squares.
+	Pieces [8]Piece
+}
+
+// DoAtomicMove executes a move under the rules of Atomic, returning the pieces which exploded so that UndoAtomicMove can revert it.
+// A capture removes the capturing piece along with every piece other than a pawn on the squares next to the capture.
+func (board *Board) DoAtomicMove(move Move) Explosion {
+	board.DoMove(move)
+	if !move.IsCapture() {
+		return Explosion{}
+	}
+
+	end := squareOf(move.End)
+	board.remove(end)
+
+	explosion := Explosion{}
+	pawns := board.Pieces(WhitePawn) | board.Pieces(BlackPawn)
+	explosion.Squares = kingAttacks[end] & board.Occupied() &^ pawns
+	i := 0
+	for squares := explosion.Squares; squares != 0; i++ {
+		explosion.Pieces[i] = board.remove(squares.popSquare())
+	}
+	return explosion
+}
+
+// UndoAtomicMove reverts a move made by DoAtomicMove, restoring the exploded pieces.
+// Move.Piece must be set to the moving piece.
+func (board *Board) UndoAtomicMove(move Move, explosion Explosion) {
+	if move.IsCapture() {
+		i := 0
+		for squares := explosion.Squares; squares != 0; i++ {
+			board.put(squares.popSquare(), explosion.Pieces[i])
+		}
+
+		// The pawn is put back in place of a promoted piece, which UndoMove returns to the start square in the same way
+		board.put(squareOf(move.End), move.Piece)
+	}
+	board.UndoMove(move)
+}
+
+// isInAtomicCheck returns whether the given color's king is attacked, which is never the case while it is next to the enemy king,
+// as the enemy king cannot capture without exploding itself.
+func (board *Board) isInAtomicCheck(color Color) bool {
+	king := board.Pieces(colorPiece(WhiteKing, color))
+	if king == 0 {
+		return false
+	}
+
+	square := king.firstSquare()
+	if kingAttacks[square]&board.Pieces(colorPiece(WhiteKing, !color)) != 0 {
+		return false
+	}
+	return board.attackersTo(square, board.Occupied())&board.ColorPieces(!color) != 0
+}
+
+// generateAtomicMoves replaces the contents of the list with the legal moves of the given stage under the rules of Atomic.
+// Explosions can remove pinned pieces and checking pieces, so each move is made to check that it does not explode the active color's king
+// and that it either explodes the enemy king or leaves the active color's king out of check.
+// Pieces in the active color's pocket are dropped as in Crazyhouse if drops are allowed.
+func (state *State) generateAtomicMoves(moves *MoveList, stage MoveStage, drops bool) error {
+	moves.Clear()
+
+	// A side whose king has exploded has lost and has no moves
+	king := state.Board.Pieces(colorPiece(WhiteKing, state.ActiveColor))
+	if king == 0 {
+		return nil
+	}
+
+	generator := state.newMoveGenerator(king.firstSquare().Position())
+	generator.atomic = true
+	generator.checkMask = allSquares
+	generator.pinned = 0
+	if !state.Board.isInAtomicCheck(state.ActiveColor) {
+		generator.checkers = 0
+	}
+
+	// Explosions can uncover checks, so every move is generated for CheckingMoves and confirmed by executing it
+	generator.stage = stage
+	if stage == CheckingMoves {
+		generator.stage = AllMoves
+	}
+	generator.appendMoves(moves)
+
+	// Drops are never captures
+	if generator.stage != CaptureMoves && drops {
+		generator.appendDrops(moves)
+	}
+
+	moves.filter(state.isLegalAtomicMove)
+	if stage == CheckingMoves {
+		moves.filter(state.givesAtomicCheck)
+	}
+	return nil
+}
+
+// isLegalAtomicMove returns whether a move generated for Atomic without regard to checks is legal.
+func (state *State) isLegalAtomicMove(move Move) bool {
+	color := state.ActiveColor
+	explosion := state.Board.DoAtomicMove(move)
+	legal := state.Board.Pieces(colorPiece(WhiteKing, color)) != 0 &&
+		(state.Board.Pieces(colorPiece(WhiteKing, !color)) == 0 || !state.Board.isInAtomicCheck(color))
+	state.Board.UndoAtomicMove(move, explosion)
+	return legal
+}
+
+// givesAtomicCheck returns whether the move puts the enemy king in check under the rules of Atomic.
+func (state *State) givesAtomicCheck(move Move) bool {
+	explosion := state.Board.DoAtomicMove(move)
+	isInCheck := state.Board.isInAtomicCheck(!state.ActiveColor)
+	state.Board.UndoAtomicMove(move, explosion)
+	return isInCheck
+}
+
+type atomicVariant struct {
+	standardVariant
+}
+
+func (atomicVariant) Name() string {
+	return "Atomic"
+}
+
+func (atomicVariant) Outcome(state *State, legalMoves []Move) Outcome {
+	for _, color := range [2]Color{state.ActiveColor, !state.ActiveColor} {
+		if state.Board.Pieces(colorPiece(WhiteKing, color)) == 0 {
+			return winFor(!color, KingExploded)
+		}
+	}
+	return Outcome{Ongoing, Unterminated}
+}
+
+// HasInsufficientMaterialToWin returns whether the color has only its king, as any other piece may be able to explode the enemy king.
+func (atomicVariant) HasInsufficientMaterialToWin(state *State, color Color) bool {
+	return state.Board.ColorPieces(color) == state.Board.Pieces(colorPiece(WhiteKing, color))
+}
+
+func (atomicVariant) Rules() Rules {
+	return Rules{Explosions: true}
+}
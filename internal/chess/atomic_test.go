@@ -0,0 +1,129 @@
+package chess
+
+import "testing"
+
+func TestAtomicMoveCount(t *testing.T) {
+	tests := []struct {
+		fen      string
+		depth    int
+		expected int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 4, 197326},
+		{"rn2kb1r/1pp1p2p/p2q1pp1/3P4/2P3b1/4PN2/PP3PPP/R2QKB1R b KQkq - 0 1", 3, 45237},
+		{"rn1qkb1r/p5pp/2p5/3p4/N3P3/5P2/PPP4P/R1BQK3 w Qkq - 0 1", 3, 23353},
+	}
+
+	for _, test := range tests {
+		state, err := ParseVariantFEN(test.fen, Atomic)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		testMoveCount(t, state, test.expected, test.depth)
+	}
+}
+
+func TestExplosion(t *testing.T) {
+	fen := "4k3/8/2q5/3n1b2/2p1P3/8/8/4K3 w - - 0 1"
+	state, err := ParseVariantFEN(fen, Atomic)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The queen next to the capture explodes, but the pawn and the bishop further away do not
+	undo := state.DoMove(Move{Start: Position{4, 4}, End: Position{3, 3}})
+	expected := "4k3/8/8/5b2/2p5/8/8/4K3 b - - 0 1"
+	if state.FEN() != expected {
+		t.Errorf("incorrect position after the explosion: expected=%s; actual=%s", expected, state.FEN())
+	}
+	if state.Hash != state.ComputeHash() {
+		t.Errorf("hash does not match computed hash after the explosion")
+	}
+
+	state.UndoMove(undo)
+	if state.FEN() != fen {
+		t.Errorf("undoing the explosion did not restore the position: %s", state.FEN())
+	}
+}
+
+func TestExplodingKingWins(t *testing.T) {
+	state, err := ParseVariantFEN("k7/1p6/8/8/8/8/8/1R2K3 w - - 0 1", Atomic)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	move, err := AlgebraicNotation("Rxb7").ToMove(game.State)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	algebraic, err := move.ToAlgebraicNotation(game.State)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if algebraic != "Rxb7#" {
+		t.Errorf("incorrect notation for exploding the king: %s", algebraic)
+	}
+
+	if err := game.DoMove(move); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.Outcome != (Outcome{WhiteWins, KingExploded}) {
+		t.Errorf("incorrect outcome after exploding the king: %s", game.Outcome)
+	}
+	if len(game.PossibleMoves) != 0 {
+		t.Errorf("a side without a king should have no moves")
+	}
+}
+
+func TestAtomicLegality(t *testing.T) {
+	tests := []struct {
+		fen     string
+		move    AlgebraicNotation
+		isLegal bool
+	}{
+		// Kings cannot capture
+		{"4k3/8/8/8/8/8/3p4/4K3 w - - 0 1", "Kxd2", false},
+		// A capture cannot explode the capturing side's own king
+		{"7k/8/8/8/8/3p4/3KQ3/8 w - - 0 1", "Qxd3", false},
+		// Exploding the enemy king is legal even if it leaves the king in check
+		{"7r/8/8/8/8/8/1pk5/1R5K w - - 0 1", "Rxb2", true},
+		// Kings may touch, as neither can capture the other
+		{"8/8/8/8/8/3k4/8/4K3 w - - 0 1", "Ke2", true},
+		// A king next to the enemy king is not in check, so pieces pinned in standard chess may move
+		{"3r4/8/8/8/8/8/3N4/3Kk3 w - - 0 1", "Nc4", true},
+	}
+
+	for _, test := range tests {
+		state, err := ParseVariantFEN(test.fen, Atomic)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		_, err = test.move.ToMove(state)
+		if (err == nil) != test.isLegal {
+			t.Errorf("%s in %s: expected legal=%t; error=%v", test.move, test.fen, test.isLegal, err)
+		}
+	}
+
+	state, err := ParseVariantFEN("3R4/8/8/8/8/3k4/4K3/8 b - - 0 1", Atomic)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if isInCheck, err := state.IsInCheck(Black); err != nil || isInCheck {
+		t.Errorf("a king next to the enemy king should not be in check")
+	}
+}
+
+func TestAtomicHashAndStages(t *testing.T) {
+	state, err := ParseVariantFEN("rn2kb1r/1pp1p2p/p2q1pp1/3P4/2P3b1/4PN2/PP3PPP/R2QKB1R b KQkq - 0 1", Atomic)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	testHashAfterMoves(t, state, 3)
+	testStagedMoveGeneration(t, state, 2)
+}
@@ -0,0 +1,70 @@
+package chess
+
+// Pin is a piece which cannot move off the line between its king and an enemy slider without exposing the king to attack.
+type Pin struct {
+	Pinned Position
+	Pinner Position
+	// Ray holds the squares the pinned piece may still move to, which are the squares between the king and the pinner and the pinner itself.
+	Ray Bitboard
+}
+
+// Attackers returns the pieces of the given color which attack the position.
+func (board *Board) Attackers(position Position, color Color) Bitboard {
+	return board.attackersTo(squareOf(position), board.Occupied()) & board.ColorPieces(color)
+}
+
+// Checkers returns the enemy pieces which are giving check to the king of the given color.
+func (board *Board) Checkers(color Color) (Bitboard, error) {
+	kingPosition, err := board.FindKing(color)
+	if err != nil {
+		return 0, err
+	}
+
+	return board.Attackers(kingPosition, !color), nil
+}
+
+// Pins returns the pieces of the given color which are pinned to their king, ordered by the square of the pinner.
+func (board *Board) Pins(color Color) ([]Pin, error) {
+	kingPosition, err := board.FindKing(color)
+	if err != nil {
+		return nil, err
+	}
+
+	kingSquare := squareOf(kingPosition)
+	pins := []Pin{}
+	for snipers := board.snipers(kingSquare, !color); snipers != 0; {
+		sniper := snipers.popSquare()
+		blockers := betweenSquares[kingSquare][sniper] & board.Occupied()
+		if blockers.Count() == 1 && blockers&board.ColorPieces(color) != 0 {
+			pins = append(pins, Pin{
+				blockers.firstSquare().Position(),
+				sniper.Position(),
+				betweenSquares[kingSquare][sniper] | squareBitboard(sniper),
+			})
+		}
+	}
+
+	return pins, nil
+}
+
+// XRayAttackers returns the bishops, rooks and queens of the given color which would attack the position if a single piece of either color in between was removed.
+// These include the pieces behind another slider in a battery, and pieces skewering or pinning an enemy piece to the position.
+func (board *Board) XRayAttackers(position Position, color Color) Bitboard {
+	square := squareOf(position)
+	xRayAttackers := Bitboard(0)
+	for snipers := board.snipers(square, color); snipers != 0; {
+		sniper := snipers.popSquare()
+		if (betweenSquares[square][sniper] & board.Occupied()).Count() == 1 {
+			xRayAttackers |= squareBitboard(sniper)
+		}
+	}
+
+	return xRayAttackers
+}
+
+// snipers returns the bishops, rooks and queens of the given color which would attack the square on an otherwise empty board.
+func (board *Board) snipers(square Square, color Color) Bitboard {
+	queens := board.Pieces(colorPiece(WhiteQueen, color))
+	return (rookAttacks(square, 0) & (board.Pieces(colorPiece(WhiteRook, color)) | queens)) |
+		(bishopAttacks(square, 0) & (board.Pieces(colorPiece(WhiteBishop, color)) | queens))
+}
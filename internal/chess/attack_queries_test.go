@@ -0,0 +1,102 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestAttackers(t *testing.T) {
+	state, err := ParseFEN("7k/8/8/3p4/4P3/2N5/8/K2Q4 w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	testBitboard(t, "white attackers of d5", state.Board.Attackers(mustPosition(t, "d5"), White), "c3", "d1", "e4")
+	testBitboard(t, "black attackers of d5", state.Board.Attackers(mustPosition(t, "d5"), Black))
+	testBitboard(t, "black attackers of e4", state.Board.Attackers(mustPosition(t, "e4"), Black), "d5")
+}
+
+func TestCheckers(t *testing.T) {
+	state, err := ParseFEN("4k3/8/8/8/1b6/8/8/4K2r w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	checkers, err := state.Board.Checkers(White)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	testBitboard(t, "checkers of white", checkers, "b4", "h1")
+
+	checkers, err = state.Board.Checkers(Black)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	testBitboard(t, "checkers of black", checkers)
+
+	if _, err := (&Board{}).Checkers(White); err == nil {
+		t.Errorf("expected an error for a board without a king")
+	}
+}
+
+func TestPins(t *testing.T) {
+	state, err := ParseFEN("4k3/4r3/8/8/1b2R3/8/3N4/4K2B w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	pins, err := state.Board.Pins(White)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(pins) != 2 {
+		t.Fatalf("expected 2 pins but found %d", len(pins))
+	}
+
+	if pins[0].Pinned != mustPosition(t, "e4") || pins[0].Pinner != mustPosition(t, "e7") {
+		t.Errorf("unexpected first pin %+v", pins[0])
+	}
+	testBitboard(t, "first pin ray", pins[0].Ray, "e2", "e3", "e4", "e5", "e6", "e7")
+
+	if pins[1].Pinned != mustPosition(t, "d2") || pins[1].Pinner != mustPosition(t, "b4") {
+		t.Errorf("unexpected second pin %+v", pins[1])
+	}
+	testBitboard(t, "second pin ray", pins[1].Ray, "b4", "c3", "d2")
+
+	pins, err = state.Board.Pins(Black)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	// The pinned white rook pins the black rook in turn
+	if len(pins) != 1 || pins[0].Pinned != mustPosition(t, "e7") || pins[0].Pinner != mustPosition(t, "e4") {
+		t.Errorf("unexpected black pins %+v", pins)
+	}
+}
+
+func TestXRayAttackers(t *testing.T) {
+	state, err := ParseFEN("7k/8/8/3p4/2P5/1P6/B2R4/3Q3K w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	testBitboard(t, "white attackers of d5", state.Board.Attackers(mustPosition(t, "d5"), White), "c4", "d2")
+	testBitboard(t, "white x-ray attackers of d5", state.Board.XRayAttackers(mustPosition(t, "d5"), White), "d1")
+}
+
+func testBitboard(t *testing.T, name string, bitboard Bitboard, squares ...string) {
+	expected := Bitboard(0)
+	for _, square := range squares {
+		expected |= PositionBitboard(mustPosition(t, square))
+	}
+
+	if bitboard != expected {
+		t.Errorf("incorrect %s: expected=%v; actual=%v", name, expected.Positions(), bitboard.Positions())
+	}
+}
+
+func mustPosition(t *testing.T, square string) Position {
+	position, err := stringToPosition(square)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	return position
+}
@@ -0,0 +1,180 @@
+package chess
+
+// magicEntry holds the data needed to look up the attacks of a sliding piece on a square with magic bitboards.
+type magicEntry struct {
+	mask    Bitboard
+	magic   uint64
+	shift   uint8
+	attacks []Bitboard
+}
+
+var (
+	knightAttacks [64]Bitboard
+	kingAttacks   [64]Bitboard
+	// pawnAttacks holds the squares attacked by a pawn of each color on each square.
+	pawnAttacks [2][64]Bitboard
+	// betweenSquares holds the squares strictly between two squares sharing a rank, file or diagonal.
+	betweenSquares [64][64]Bitboard
+	// lineSquares holds every square on the line through two squares sharing a rank, file or diagonal.
+	lineSquares [64][64]Bitboard
+
+	rookMagics   [64]magicEntry
+	bishopMagics [64]magicEntry
+)
+
+func init() {
+	for square := Square(0); square < 64; square++ {
+		position := square.Position()
+
+		for _, offset := range knightOffsets {
+			knightAttacks[square] |= offsetBitboard(position, offset)
+		}
+		for _, offset := range rookDirections {
+			kingAttacks[square] |= offsetBitboard(position, offset)
+		}
+		for _, offset := range bishopDirections {
+			kingAttacks[square] |= offsetBitboard(position, offset)
+		}
+
+		pawnAttacks[colorIndex(White)][square] = offsetBitboard(position, Position{-1, -1}) | offsetBitboard(position, Position{-1, 1})
+		pawnAttacks[colorIndex(Black)][square] = offsetBitboard(position, Position{1, -1}) | offsetBitboard(position, Position{1, 1})
+	}
+
+	initialiseMagics(&rookMagics, rookDirections, rookMagicNumbers)
+	initialiseMagics(&bishopMagics, bishopDirections, bishopMagicNumbers)
+
+	for from := Square(0); from < 64; from++ {
+		for _, directions := range [2][4]Position{rookDirections, bishopDirections} {
+			for _, direction := range directions {
+				ray := slidingAttacks(from.Position(), [4]Position{direction}, 0)
+				for to := ray; to != 0; {
+					square := to.popSquare()
+					fromRay := slidingAttacks(from.Position(), [4]Position{direction}, squareBitboard(square))
+					betweenSquares[from][square] = fromRay &^ squareBitboard(square)
+
+					opposite := MultiplyScalar(direction, -1)
+					lineSquares[from][square] = ray | squareBitboard(from) | slidingAttacks(from.Position(), [4]Position{opposite}, 0)
+				}
+			}
+		}
+	}
+}
+
+// lookup returns the attacks for the given occupied squares.
+func (entry *magicEntry) lookup(occupied Bitboard) Bitboard {
+	return entry.attacks[(uint64(occupied&entry.mask)*entry.magic)>>entry.shift]
+}
+
+// rookAttacks returns the squares attacked by a rook on the given square.
+func rookAttacks(square Square, occupied Bitboard) Bitboard {
+	return rookMagics[square].lookup(occupied)
+}
+
+// bishopAttacks returns the squares attacked by a bishop on the given square.
+func bishopAttacks(square Square, occupied Bitboard) Bitboard {
+	return bishopMagics[square].lookup(occupied)
+}
+
+// initialiseMagics fills the lookup tables of a sliding piece using its precomputed magic numbers.
+func initialiseMagics(entries *[64]magicEntry, directions [4]Position, magics [64]uint64) {
+	for square := Square(0); square < 64; square++ {
+		position := square.Position()
+		mask := slidingMask(position, directions)
+		bitCount := mask.Count()
+
+		entry := &entries[square]
+		entry.mask = mask
+		entry.magic = magics[square]
+		entry.shift = uint8(64 - bitCount)
+		entry.attacks = make([]Bitboard, 1<<bitCount)
+
+		// Enumerate every subset of the mask
+		occupied := Bitboard(0)
+		for {
+			entry.attacks[(uint64(occupied)*entry.magic)>>entry.shift] = slidingAttacks(position, directions, occupied)
+
+			occupied = (occupied - mask) & mask
+			if occupied == 0 {
+				break
+			}
+		}
+	}
+}
+
+// slidingMask returns the squares whose occupancy affects the attacks of a sliding piece, which excludes the edge of each ray.
+func slidingMask(position Position, directions [4]Position) Bitboard {
+	mask := Bitboard(0)
+	for _, direction := range directions {
+		for next := AddPositions(position, direction); isInBounds(AddPositions(next, direction)); next = AddPositions(next, direction) {
+			mask |= PositionBitboard(next)
+		}
+	}
+	return mask
+}
+
+// slidingAttacks returns the squares attacked by a sliding piece by walking along each ray until it is blocked.
+func slidingAttacks(position Position, directions [4]Position, occupied Bitboard) Bitboard {
+	attacks := Bitboard(0)
+	for _, direction := range directions {
+		if direction == (Position{}) {
+			continue
+		}
+
+		for next := AddPositions(position, direction); isInBounds(next); next = AddPositions(next, direction) {
+			attacks |= PositionBitboard(next)
+			if occupied.Contains(next) {
+				break
+			}
+		}
+	}
+	return attacks
+}
+
+// offsetBitboard returns a bitboard containing the position moved by the offset, or an empty bitboard if it is off the board.
+func offsetBitboard(position Position, offset Position) Bitboard {
+	next := AddPositions(position, offset)
+	if !isInBounds(next) {
+		return 0
+	}
+	return PositionBitboard(next)
+}
+
+// rookMagicNumbers and bishopMagicNumbers map the relevant occupied squares of each square to a unique index into its attack table.
+// They were found by a search over sparse random numbers.
+var rookMagicNumbers = [64]uint64{
+	0x1080004008801020, 0x0840092002c03000, 0x1900200010400900, 0x0880100008000480,
+	0x4200100420080200, 0x8100020100080400, 0x0200040110886200, 0x0200008040220411,
+	0x0404800084400220, 0x0000401000402000, 0x0086001081220440, 0x0408800800100280,
+	0x000a001201040820, 0x8848800200840080, 0x4001000100040200, 0x0442000102105084,
+	0x9080010020804100, 0x0040404000201009, 0x0000808010002009, 0x2200090021d00100,
+	0x0008008008040080, 0x0004004002010040, 0x0011040008015042, 0x00000a0001768104,
+	0x0000800080204009, 0x2010004140002001, 0x9800200280100080, 0x1000100080080080,
+	0x0442000a00049020, 0x2100040080020080, 0x0800120400900148, 0x0010040a00128541,
+	0x2800804000800030, 0x1010002000400041, 0x4000200011004100, 0x0610008410800800,
+	0x0400802402800800, 0xc100020080800400, 0x0002000802000401, 0x0182085882000401,
+	0x0220204000808000, 0x2860100040024022, 0x0001002004110040, 0x99101042000a0020,
+	0x0004080004008080, 0x0010040002008080, 0x2012004881020004, 0x8300842444820011,
+	0x0088403882010200, 0x0820400080210100, 0x0110910040a00300, 0x0801100280080480,
+	0x0242009008200600, 0x1002000489500200, 0x0040800200010080, 0x0091800041000080,
+	0x0000209300488001, 0x04c1002414824001, 0x020020000b001041, 0x7000100004200901,
+	0x8002002004100802, 0x30010002084c0007, 0x0888221800813004, 0x4000002840840112,
+}
+
+var bishopMagicNumbers = [64]uint64{
+	0x10102002004a1420, 0x8020040400584008, 0x10510800811201c8, 0x5204042080000088,
+	0x2204106880000002, 0x1401042004000000, 0x0400880410042004, 0x0028208200a02020,
+	0x1500241990010e00, 0x8001200182020a40, 0x40004101030b0000, 0x8002041042000100,
+	0x4010011041020038, 0x0000010421044000, 0x1500210808020a00, 0x8000088400880520,
+	0x0405004010040100, 0x1005823210040108, 0x2708008102040011, 0x4048200404009100,
+	0x0018104101400024, 0x0003000601190101, 0x8004803108491000, 0x8014241200820800,
+	0x0006e080100c3040, 0x0501044a11041800, 0x9020300008004045, 0x0894080000220040,
+	0x1001010083104000, 0x5004030040900080, 0x000400422c012400, 0x0002128698404812,
+	0x1010108404900440, 0x0928021182084100, 0x2006080409020024, 0x1010202020180080,
+	0xa010008200202200, 0x2098015100019004, 0x0002041440810811, 0x802a02020000b098,
+	0x0009015090004060, 0x4000821082081001, 0x0100210040420800, 0x0800004010488a00,
+	0x2000081104004040, 0x4c8e029015000082, 0x0420340322224842, 0x1298260043400210,
+	0x0000822802400008, 0x00008a0101600000, 0x3040003412080021, 0x3040290220884800,
+	0x4a1500401041004a, 0x8010200282020781, 0x0020203142209091, 0x0070300600902110,
+	0x0040808800b62048, 0x0000810400c44420, 0x00080400440c0441, 0x8340080020840411,
+	0x0000000104208200, 0x0000800810d00080, 0x0400530411080200, 0x4040702400932244,
+}
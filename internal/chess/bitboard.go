@@ -0,0 +1,96 @@
+package chess
+
+import "math/bits"
+
+// Bitboard is a set of squares, where bit X*8+Y represents the Position{X, Y}.
+type Bitboard uint64
+
+// Square is the index of a position on the board, from 0 for a8 to 63 for h1.
+type Square int8
+
+const (
+	fileA      Bitboard = 0x0101010101010101
+	fileH      Bitboard = fileA << 7
+	rank8      Bitboard = 0xff
+	rank1      Bitboard = rank8 << 56
+	allSquares Bitboard = ^Bitboard(0)
+	// lightSquares holds the light squares, starting with a8.
+	lightSquares Bitboard = 0xaa55aa55aa55aa55
+)
+
+// squareOf returns the square index of a position.
+func squareOf(position Position) Square {
+	return Square(position.X*8 + position.Y)
+}
+
+// Position returns the position of a square index.
+func (square Square) Position() Position {
+	return Position{int8(square) / 8, int8(square) % 8}
+}
+
+// squareBitboard returns a bitboard containing only the given square.
+func squareBitboard(square Square) Bitboard {
+	return Bitboard(1) << square
+}
+
+// PositionBitboard returns a bitboard containing only the given position.
+func PositionBitboard(position Position) Bitboard {
+	return squareBitboard(squareOf(position))
+}
+
+// Contains returns whether the position is in the set.
+func (bitboard Bitboard) Contains(position Position) bool {
+	return bitboard&PositionBitboard(position) != 0
+}
+
+// Count returns the number of squares in the set.
+func (bitboard Bitboard) Count() int {
+	return bits.OnesCount64(uint64(bitboard))
+}
+
+// Positions returns the positions in the set, ordered from a8 to h1.
+func (bitboard Bitboard) Positions() []Position {
+	positions := make([]Position, 0, bitboard.Count())
+	for bitboard != 0 {
+		positions = append(positions, bitboard.popSquare().Position())
+	}
+	return positions
+}
+
+// firstSquare returns the lowest square in a non-empty set.
+func (bitboard Bitboard) firstSquare() Square {
+	return Square(bits.TrailingZeros64(uint64(bitboard)))
+}
+
+// popSquare removes and returns the lowest square in a non-empty set.
+func (bitboard *Bitboard) popSquare() Square {
+	square := bitboard.firstSquare()
+	*bitboard &= *bitboard - 1
+	return square
+}
+
+// colorIndex returns the index used for a color in per-color tables.
+func colorIndex(color Color) int {
+	if color == Black {
+		return 1
+	}
+	return 0
+}
+
+// pieceIndex returns the index used for a piece in per-piece tables.
+func pieceIndex(piece Piece) int {
+	return int(piece) + 6
+}
+
+// pieceColor returns the color of a non-empty piece.
+func pieceColor(piece Piece) Color {
+	return piece > 0
+}
+
+// colorPiece returns the piece of the given color with the same type as the given white piece.
+func colorPiece(whitePiece Piece, color Color) Piece {
+	if color == Black {
+		return -whitePiece
+	}
+	return whitePiece
+}
@@ -5,7 +5,12 @@ import (
 )
 
 // Board represents a chess board.
-type Board [8][8]Piece
+// The piece on each square is stored alongside a bitboard for each piece and color, which are always kept in sync.
+type Board struct {
+	squares [64]Piece
+	pieces  [13]Bitboard
+	colors  [2]Bitboard
+}
 
 // Position represents a location on a Board.
 type Position struct {
@@ -20,7 +25,7 @@ type PositionOpt struct {
 
 // InitialiseBoard returns the default starting position of a chess board.
 func InitialiseBoard() Board {
-	return Board{
+	return NewBoard([8][8]Piece{
 		{BlackRook, BlackKnight, BlackBishop, BlackQueen, BlackKing, BlackBishop, BlackKnight, BlackRook},
 		{BlackPawn, BlackPawn, BlackPawn, BlackPawn, BlackPawn, BlackPawn, BlackPawn, BlackPawn},
 		{EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare},
@@ -29,7 +34,18 @@ func InitialiseBoard() Board {
 		{EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare},
 		{WhitePawn, WhitePawn, WhitePawn, WhitePawn, WhitePawn, WhitePawn, WhitePawn, WhitePawn},
 		{WhiteRook, WhiteKnight, WhiteBishop, WhiteQueen, WhiteKing, WhiteBishop, WhiteKnight, WhiteRook},
+	})
+}
+
+// NewBoard creates a board from the piece on each square, indexed by rank from the eighth rank and then by file.
+func NewBoard(squares [8][8]Piece) Board {
+	board := Board{}
+	for i, rank := range squares {
+		for j, piece := range rank {
+			board.SetSquare(Position{int8(i), int8(j)}, piece)
+		}
 	}
+	return board
 }
 
 // PositionsToOptionalPositions converts a slice of Position ot a slice of PositionOpt where Ok is true.
@@ -54,7 +70,7 @@ func BoardToDisplayString(board Board) string {
 		stringBoard += "  |    |    |    |    |    |    |    |    |\n"
 		stringBoard += fmt.Sprintf("%d ", 8-i)
 		for j := 0; j < 8; j++ {
-			stringBoard += fmt.Sprintf("| %s ", PieceToDisplayString(board[i][j]))
+			stringBoard += fmt.Sprintf("| %s ", PieceToDisplayString(board.GetSquare(Position{int8(i), int8(j)})))
 		}
 		stringBoard += "|\n"
 		stringBoard += "  |    |    |    |    |    |    |    |    |\n"
@@ -66,79 +82,139 @@ func BoardToDisplayString(board Board) string {
 
 // DoMove takes in a board and a move and executes the move, returning the updated board.
 func (board *Board) DoMove(move Move) {
-	piece := board[move.Start.X][move.Start.Y]
+	start := squareOf(move.Start)
+	end := squareOf(move.End)
+	piece := board.squares[start]
 
 	switch move.Flag {
 	case None:
-		board[move.Start.X][move.Start.Y] = EmptySquare
-		board[move.End.X][move.End.Y] = piece
+		board.remove(start)
+		board.remove(end)
+		board.put(end, piece)
 	case EnPassant:
-		board[move.Start.X][move.Start.Y] = EmptySquare
-		board[move.Start.X][move.End.Y] = EmptySquare
-		board[move.End.X][move.End.Y] = piece
+		board.remove(start)
+		board.remove(squareOf(Position{move.Start.X, move.End.Y}))
+		board.put(end, piece)
 	case QueenSideCastle:
-		queenSideRook := getRookColorForKing(piece)
-		board[move.Start.X][4] = EmptySquare
-		board[move.Start.X][3] = queenSideRook
-		board[move.Start.X][2] = piece
-		board[move.Start.X][0] = EmptySquare
+		board.remove(start)
+		board.remove(end)
+		board.put(squareOf(Position{move.Start.X, 3}), getRookColorForKing(piece))
+		board.put(squareOf(Position{move.Start.X, 2}), piece)
 	case KingSideCastle:
-		kingSideRook := getRookColorForKing(piece)
-		board[move.Start.X][4] = EmptySquare
-		board[move.Start.X][5] = kingSideRook
-		board[move.Start.X][6] = piece
-		board[move.Start.X][7] = EmptySquare
+		board.remove(start)
+		board.remove(end)
+		board.put(squareOf(Position{move.Start.X, 5}), getRookColorForKing(piece))
+		board.put(squareOf(Position{move.Start.X, 6}), piece)
 	case PromoteToQueen:
-		queen := getQueenColorForPawn(piece)
-		board[move.Start.X][move.Start.Y] = EmptySquare
-		board[move.End.X][move.End.Y] = queen
+		board.remove(start)
+		board.remove(end)
+		board.put(end, getQueenColorForPawn(piece))
 	case PromoteToRook:
-		rook := getRookColorForPawn(piece)
-		board[move.Start.X][move.Start.Y] = EmptySquare
-		board[move.End.X][move.End.Y] = rook
+		board.remove(start)
+		board.remove(end)
+		board.put(end, getRookColorForPawn(piece))
 	case PromoteToBishop:
-		bishop := getBishopColorForPawn(piece)
-		board[move.Start.X][move.Start.Y] = EmptySquare
-		board[move.End.X][move.End.Y] = bishop
+		board.remove(start)
+		board.remove(end)
+		board.put(end, getBishopColorForPawn(piece))
 	case PromoteToKnight:
-		knight := getKnightColorForPawn(piece)
-		board[move.Start.X][move.Start.Y] = EmptySquare
-		board[move.End.X][move.End.Y] = knight
+		board.remove(start)
+		board.remove(end)
+		board.put(end, getKnightColorForPawn(piece))
+	case PromoteToKing:
+		board.remove(start)
+		board.remove(end)
+		board.put(end, colorPiece(WhiteKing, pieceColor(piece)))
+	case Drop:
+		board.put(end, move.Piece)
 	}
 }
 
+// UndoMove takes in a board and a move which was executed on it and reverts the move.
 func (board *Board) UndoMove(move Move) {
-	piece := board[move.End.X][move.End.Y]
+	start := squareOf(move.Start)
+	end := squareOf(move.End)
+	piece := board.squares[end]
 
 	switch move.Flag {
 	case None:
-		board[move.Start.X][move.Start.Y] = piece
-		board[move.End.X][move.End.Y] = move.Captured
+		board.remove(end)
+		board.put(start, piece)
+		board.put(end, move.Captured)
 	case EnPassant:
-		board[move.Start.X][move.Start.Y] = piece
-		board[move.Start.X][move.End.Y] = move.Captured
-		board[move.End.X][move.End.Y] = EmptySquare
+		board.remove(end)
+		board.put(start, piece)
+		board.put(squareOf(Position{move.Start.X, move.End.Y}), move.Captured)
 	case QueenSideCastle:
-		queenSideRook := getRookColorForKing(piece)
-		board[move.Start.X][4] = piece
-		board[move.Start.X][3] = EmptySquare
-		board[move.Start.X][2] = EmptySquare
-		board[move.Start.X][0] = queenSideRook
+		king := board.remove(squareOf(Position{move.Start.X, 2}))
+		board.remove(squareOf(Position{move.Start.X, 3}))
+		board.put(end, getRookColorForKing(king))
+		board.put(start, king)
 	case KingSideCastle:
-		kingSideRook := getRookColorForKing(piece)
-		board[move.Start.X][4] = piece
-		board[move.Start.X][5] = EmptySquare
-		board[move.Start.X][6] = EmptySquare
-		board[move.Start.X][7] = kingSideRook
-	case PromoteToQueen, PromoteToRook, PromoteToBishop, PromoteToKnight:
-		board[move.Start.X][move.Start.Y] = getSameColorPawn(board[move.End.X][move.End.Y])
-		board[move.End.X][move.End.Y] = move.Captured
+		king := board.remove(squareOf(Position{move.Start.X, 6}))
+		board.remove(squareOf(Position{move.Start.X, 5}))
+		board.put(end, getRookColorForKing(king))
+		board.put(start, king)
+	case PromoteToQueen, PromoteToRook, PromoteToBishop, PromoteToKnight, PromoteToKing:
+		board.remove(end)
+		board.put(start, getSameColorPawn(piece))
+		board.put(end, move.Captured)
+	case Drop:
+		board.remove(end)
 	}
 }
 
 // GetSquare returns the piece at the position.
 func (board *Board) GetSquare(position Position) Piece {
-	return board[position.X][position.Y]
+	return board.squares[squareOf(position)]
+}
+
+// SetSquare places a piece at the position, replacing any piece already there.
+func (board *Board) SetSquare(position Position, piece Piece) {
+	square := squareOf(position)
+	board.remove(square)
+	board.put(square, piece)
+}
+
+// Occupied returns the set of squares containing any piece.
+func (board *Board) Occupied() Bitboard {
+	return board.colors[0] | board.colors[1]
+}
+
+// ColorPieces returns the set of squares containing pieces of the given color.
+func (board *Board) ColorPieces(color Color) Bitboard {
+	return board.colors[colorIndex(color)]
+}
+
+// Pieces returns the set of squares containing the given piece.
+func (board *Board) Pieces(piece Piece) Bitboard {
+	return board.pieces[pieceIndex(piece)]
+}
+
+// put places a piece on an empty square.
+func (board *Board) put(square Square, piece Piece) {
+	if piece == EmptySquare {
+		return
+	}
+
+	bit := squareBitboard(square)
+	board.squares[square] = piece
+	board.pieces[pieceIndex(piece)] |= bit
+	board.colors[colorIndex(pieceColor(piece))] |= bit
+}
+
+// remove empties a square, returning the piece which was on it.
+func (board *Board) remove(square Square) Piece {
+	piece := board.squares[square]
+	if piece == EmptySquare {
+		return piece
+	}
+
+	bit := squareBitboard(square)
+	board.squares[square] = EmptySquare
+	board.pieces[pieceIndex(piece)] &^= bit
+	board.colors[colorIndex(pieceColor(piece))] &^= bit
+	return piece
 }
 
 // AddPositions returns the component-wise addition of two positions.
@@ -0,0 +1,94 @@
+package chess
+
+import "fmt"
+
+// Chess960StandardPosition is the Chess960 starting position number of the standard chess starting position.
+const Chess960StandardPosition = 518
+
+// chess960KnightPlacements gives the indices of the two knights among the five squares left after placing the bishops and queen.
+var chess960KnightPlacements = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4},
+	{1, 2}, {1, 3}, {1, 4},
+	{2, 3}, {2, 4},
+	{3, 4},
+}
+
+// InitialiseChess960State returns the starting state for the given Chess960 position number, between 0 and 959.
+func InitialiseChess960State(positionNumber int) (State, error) {
+	board, err := InitialiseChess960Board(positionNumber)
+	if err != nil {
+		return State{}, err
+	}
+
+	state := InitialiseState()
+	state.Board = board
+
+	for _, color := range [2]Color{White, Black} {
+		kingFile, _ := board.getCastlingKingFile(color)
+		state.CastlingKingFiles.setCastlingKingFile(color, kingFile)
+		for _, kingSide := range [2]bool{true, false} {
+			state.CastlingRookFiles.setCastlingRookFile(color, kingSide, board.getOutermostRookFile(color, kingSide, kingFile))
+		}
+	}
+	state.Hash = state.ComputeHash()
+
+	return state, nil
+}
+
+// IsChess960 returns whether a castling king or rook starts away from the e, a and h files, which is only possible in Chess960.
+// A Chess960 position with its kings and rooks on the standard files cannot be told apart from standard chess.
+func (state *State) IsChess960() bool {
+	return state.CastlingKingFiles != standardCastlingKingFiles || state.CastlingRookFiles != standardCastlingRookFiles
+}
+
+// InitialiseChess960Board returns the starting board for the given Chess960 position number, between 0 and 959.
+// Positions are numbered using Scharnagl's scheme, in which position 518 is the standard starting position.
+func InitialiseChess960Board(positionNumber int) (Board, error) {
+	if positionNumber < 0 || positionNumber >= 960 {
+		return Board{}, fmt.Errorf("chess960 position number %d is out of range", positionNumber)
+	}
+
+	backRank := [8]Piece{}
+	placeInEmpty := func(piece Piece, emptyIndex int) {
+		for file := range backRank {
+			if backRank[file] != EmptySquare {
+				continue
+			}
+			if emptyIndex == 0 {
+				backRank[file] = piece
+				return
+			}
+			emptyIndex--
+		}
+	}
+
+	n := positionNumber
+
+	// Bishops are placed on opposite colored squares
+	backRank[2*(n%4)+1] = WhiteBishop
+	n /= 4
+	backRank[2*(n%4)] = WhiteBishop
+	n /= 4
+
+	placeInEmpty(WhiteQueen, n%6)
+	n /= 6
+
+	// The second knight is placed first so that the index of the first knight is unaffected
+	knights := chess960KnightPlacements[n]
+	placeInEmpty(WhiteKnight, knights[1])
+	placeInEmpty(WhiteKnight, knights[0])
+
+	// The king is always placed between the rooks
+	placeInEmpty(WhiteRook, 0)
+	placeInEmpty(WhiteKing, 0)
+	placeInEmpty(WhiteRook, 0)
+
+	board := Board{}
+	for file, piece := range backRank {
+		board.SetSquare(Position{0, int8(file)}, -piece)
+		board.SetSquare(Position{1, int8(file)}, BlackPawn)
+		board.SetSquare(Position{6, int8(file)}, WhitePawn)
+		board.SetSquare(Position{7, int8(file)}, piece)
+	}
+	return board, nil
+}
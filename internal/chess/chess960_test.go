@@ -0,0 +1,227 @@
+package chess
+
+import "testing"
+
+func TestChess960StandardPosition(t *testing.T) {
+	state, err := InitialiseChess960State(Chess960StandardPosition)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if state != InitialiseState() {
+		t.Errorf("chess960 position %d should be the standard starting position: %s", Chess960StandardPosition, state.FEN())
+	}
+}
+
+func TestChess960StartingPositions(t *testing.T) {
+	tests := []struct {
+		positionNumber int
+		fen            string
+		shredderFEN    string
+		xfen           string
+	}{
+		{0, "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1", "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1", "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1"},
+		{518, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"},
+		{959, "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w CAca - 0 1", "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w CAca - 0 1", "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w KQkq - 0 1"},
+	}
+
+	for _, test := range tests {
+		state, err := InitialiseChess960State(test.positionNumber)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if state.FEN() != test.fen {
+			t.Errorf("incorrect FEN for position %d: expected=%s; actual=%s", test.positionNumber, test.fen, state.FEN())
+		}
+		if state.ShredderFEN() != test.shredderFEN {
+			t.Errorf("incorrect Shredder-FEN for position %d: expected=%s; actual=%s", test.positionNumber, test.shredderFEN, state.ShredderFEN())
+		}
+
+		parsed, err := ParseFEN(test.shredderFEN)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if parsed != state {
+			t.Errorf("parsed Shredder-FEN does not match position %d", test.positionNumber)
+		}
+
+		parsed, err = ParseChess960FEN(test.xfen, Standard)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if parsed != state {
+			t.Errorf("parsed X-FEN does not match position %d", test.positionNumber)
+		}
+	}
+}
+
+func TestChess960PositionsAreUniqueAndValid(t *testing.T) {
+	seen := map[Board]int{}
+	for positionNumber := 0; positionNumber < 960; positionNumber++ {
+		state, err := InitialiseChess960State(positionNumber)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if previous, ok := seen[state.Board]; ok {
+			t.Errorf("positions %d and %d are identical", previous, positionNumber)
+		}
+		seen[state.Board] = positionNumber
+
+		if problems := state.Validate(); len(problems) != 0 {
+			t.Errorf("position %d is invalid: %v", positionNumber, problems)
+		}
+	}
+
+	if _, err := InitialiseChess960State(960); err == nil {
+		t.Errorf("expected position 960 to be out of range")
+	}
+}
+
+func TestIsChess960(t *testing.T) {
+	tests := []struct {
+		positionNumber int
+		expected       bool
+	}{
+		{0, true},
+		{414, false},
+		{Chess960StandardPosition, false},
+	}
+
+	for _, test := range tests {
+		state, err := InitialiseChess960State(test.positionNumber)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if state.IsChess960() != test.expected {
+			t.Errorf("incorrect IsChess960 for position %d: %t", test.positionNumber, !test.expected)
+		}
+	}
+}
+
+func TestChess960Castling(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     AlgebraicNotation
+		expected string
+	}{
+		{"rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1", "O-O", "rk5r/8/8/8/8/8/8/R4RK1 b ha - 1 1"},
+		{"rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1", "O-O-O", "rk5r/8/8/8/8/8/8/2KR3R b ha - 1 1"},
+		{"6k1/8/8/8/8/8/8/6KR w H - 0 1", "O-O", "6k1/8/8/8/8/8/8/5RK1 b - - 1 1"},
+		{"1r2k1r1/8/8/8/8/8/8/4K3 b bg - 0 1", "O-O-O", "2kr2r1/8/8/8/8/8/8/4K3 w - - 1 2"},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		move, err := test.move.ToMove(state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		moves, err := state.GenerateAllMoves()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		isGenerated := false
+		for _, generated := range moves {
+			isGenerated = isGenerated || generated == move
+		}
+		if !isGenerated {
+			t.Errorf("castling move %s was not generated for %s", test.move, test.fen)
+		}
+
+		algebraic, err := move.ToAlgebraicNotation(state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if algebraic != test.move {
+			t.Errorf("incorrect notation for castling: expected=%s; actual=%s", test.move, algebraic)
+		}
+
+		initial := state
+		undo := state.DoMove(move)
+		if state.FEN() != test.expected {
+			t.Errorf("incorrect state after %s: expected=%s; actual=%s", test.move, test.expected, state.FEN())
+		}
+
+		state.UndoMove(undo)
+		if state != initial {
+			t.Errorf("incorrect state after undoing %s: expected=%s; actual=%s", test.move, test.fen, state.FEN())
+		}
+	}
+}
+
+func TestStandardCastlingRightsAreNotChess960(t *testing.T) {
+	state, err := ParseFEN("r3k2r/8/8/8/8/8/8/R4K1R w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if state.CastlingKingFiles != standardCastlingKingFiles || state.CastlingRookFiles != standardCastlingRookFiles {
+		t.Errorf("KQkq should refer to the standard castling squares: %+v, %+v", state.CastlingKingFiles, state.CastlingRookFiles)
+	}
+
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, move := range moves {
+		if move.IsCastle() {
+			t.Errorf("castling should not be offered from f1: %s", move)
+		}
+	}
+
+	if _, err := ParseFEN("r3k2r/8/8/8/8/8/5K2/R6R w HAkq - 0 1"); err == nil {
+		t.Errorf("expected an error for a Chess960 castling right without a king on the back rank")
+	}
+}
+
+func TestChess960CastlingRookShieldsKing(t *testing.T) {
+	state, err := ParseFEN("8/8/8/8/8/8/8/rRK3k1 w B - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, move := range moves {
+		if move.IsCastle() {
+			t.Errorf("castling should be illegal when the rook shields the king's final square")
+		}
+	}
+}
+
+// Reference:
+// https://www.chessprogramming.org/Chess960_Perft_Results
+
+func TestMoveCountChess960Position1Depth4(t *testing.T) {
+	state, err := ParseFEN("bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	testMoveCount(t, state, 326672, 4)
+}
+
+func TestMoveCountChess960Position2Depth4(t *testing.T) {
+	state, err := ParseFEN("2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	testMoveCount(t, state, 667366, 4)
+}
+
+func TestMoveCountChess960Position3Depth4(t *testing.T) {
+	state, err := ParseFEN("b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	testMoveCount(t, state, 273318, 4)
+}
@@ -0,0 +1,145 @@
+package chess
+
+import "strings"
+
+// pocketPieces are the white pieces which may be held in a pocket, in the order of a Pocket's counts.
+var pocketPieces = [5]Piece{WhitePawn, WhiteKnight, WhiteBishop, WhiteRook, WhiteQueen}
+
+// maxPocketCount is the most pieces of a single type which are hashed separately, which is the number of pawns on both sides.
+const maxPocketCount = 16
+
+// Pocket holds the number of pawns, knights, bishops, rooks and queens a side has captured and may drop.
+type Pocket [5]int8
+
+// Pockets holds the pocket of each side in variants with drops such as Crazyhouse.
+type Pockets struct {
+	White Pocket
+	Black Pocket
+}
+
+// Get returns the pocket of the given color.
+func (pockets Pockets) Get(color Color) Pocket {
+	if color == Black {
+		return pockets.Black
+	}
+	return pockets.White
+}
+
+// Count returns the number of pieces of the same type as the given piece of either color in the pocket.
+func (pocket Pocket) Count(piece Piece) int {
+	return int(pocket[pocketIndex(piece)])
+}
+
+// String returns the pieces in both pockets as they are written in FEN, with white's pieces first, such as QNPbp.
+func (pockets Pockets) String() string {
+	var builder strings.Builder
+	for _, color := range [2]Color{White, Black} {
+		pocket := pockets.Get(color)
+		for i := len(pocketPieces) - 1; i >= 0; i-- {
+			for count := int8(0); count < pocket[i]; count++ {
+				builder.WriteRune(pieceToFENPiece(colorPiece(pocketPieces[i], color)))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// add puts a piece into the pocket of the piece's color.
+func (pockets *Pockets) add(piece Piece) {
+	if pieceColor(piece) == Black {
+		pockets.Black[pocketIndex(piece)]++
+	} else {
+		pockets.White[pocketIndex(piece)]++
+	}
+}
+
+// remove takes a piece out of the pocket of the piece's color.
+func (pockets *Pockets) remove(piece Piece) {
+	if pieceColor(piece) == Black {
+		pockets.Black[pocketIndex(piece)]--
+	} else {
+		pockets.White[pocketIndex(piece)]--
+	}
+}
+
+// pocketIndex returns the index of a pawn, knight, bishop, rook or queen of either color in a Pocket.
+func pocketIndex(piece Piece) int {
+	if piece < 0 {
+		piece = -piece
+	}
+	return int(piece) - 1
+}
+
+// updatePockets moves captured pieces into the capturer's pocket, takes dropped pieces out of it and keeps track of which pieces were promoted.
+// A captured promoted piece goes into the pocket as a pawn.
+func (state *State) updatePockets(move Move) {
+	if move.IsCapture() {
+		captured := move.End
+		if move.Flag == EnPassant {
+			captured = Position{move.Start.X, move.End.Y}
+		}
+
+		// The captured piece changes color, so it is converted to the white piece of the same type
+		piece := getWhitePiece(move.Captured)
+		if state.Promoted.Contains(captured) {
+			piece = WhitePawn
+			state.Promoted &^= PositionBitboard(captured)
+		}
+		state.Pockets.add(colorPiece(piece, state.ActiveColor))
+	}
+
+	switch {
+	case move.Flag == Drop:
+		state.Pockets.remove(move.Piece)
+	case move.IsPromotion():
+		state.Promoted |= PositionBitboard(move.End)
+	case state.Promoted.Contains(move.Start):
+		state.Promoted = state.Promoted&^PositionBitboard(move.Start) | PositionBitboard(move.End)
+	}
+}
+
+// appendDrops appends the legal drops of the pieces in the active color's pocket.
+// A drop cannot uncover an attack on the king, so it is legal whenever it is onto an empty square which resolves any check.
+func (generator *moveGenerator) appendDrops(moves *MoveList) {
+	state := generator.state
+	pocket := state.Pockets.Get(state.ActiveColor)
+	targets := generator.checkMask &^ generator.occupied
+
+	for i, whitePiece := range pocketPieces {
+		if pocket[i] == 0 {
+			continue
+		}
+
+		piece := colorPiece(whitePiece, state.ActiveColor)
+		pieceTargets := targets
+		if whitePiece == WhitePawn {
+			pieceTargets &^= rank1 | rank8
+		}
+		if generator.stage == CheckingMoves {
+			pieceTargets &= generator.getCheckSquares(piece)
+		}
+
+		for pieceTargets != 0 {
+			to := pieceTargets.popSquare()
+			moves.Add(encodeMove(to, to, Drop, EmptySquare, piece))
+		}
+	}
+}
+
+type crazyhouseVariant struct {
+	standardVariant
+}
+
+func (crazyhouseVariant) Name() string {
+	return "Crazyhouse"
+}
+
+// HasInsufficientMaterialToWin returns whether only the kings are left with nothing in either pocket, as any other piece could be captured and dropped.
+func (crazyhouseVariant) HasInsufficientMaterialToWin(state *State, color Color) bool {
+	kings := state.Board.Pieces(WhiteKing) | state.Board.Pieces(BlackKing)
+	return state.Board.Occupied() == kings && state.Pockets == Pockets{}
+}
+
+func (crazyhouseVariant) Rules() Rules {
+	return Rules{Drops: true}
+}
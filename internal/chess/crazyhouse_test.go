@@ -0,0 +1,219 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+const crazyhouseTestFEN = "r1bqk2r/pppp1ppp/2n2n2/2b1p3/2B1P3/2N2N2/PPPP1PPP/R1BQK2R[NPbp] w KQkq - 0 5"
+
+func TestCrazyhouseFEN(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1",
+		crazyhouseTestFEN,
+		"r2Q~k2r/8/8/8/8/8/8/4K1q~1[QRBNPqrbnp] b kq - 3 30",
+	}
+
+	for _, fen := range fens {
+		state, err := ParseVariantFEN(fen, Crazyhouse)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if state.FEN() != fen {
+			t.Errorf("FEN did not round trip: expected=%s; actual=%s", fen, state.FEN())
+		}
+	}
+
+	state, err := ParseVariantFEN(crazyhouseTestFEN, Crazyhouse)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if state.Pockets != (Pockets{Pocket{1, 1, 0, 0, 0}, Pocket{1, 0, 1, 0, 0}}) {
+		t.Errorf("incorrect pockets: %+v", state.Pockets)
+	}
+}
+
+func TestCrazyhouseFENErrors(t *testing.T) {
+	tests := []struct {
+		fen     string
+		variant Variant
+		column  int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1", Standard, 44},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[Q w KQkq - 0 1", Crazyhouse, 45},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[QK] w KQkq - 0 1", Crazyhouse, 46},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[X] w KQkq - 0 1", Crazyhouse, 45},
+		{"~rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1", Crazyhouse, 1},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/3~RNBQKBNR[] w KQkq - 0 1", Crazyhouse, 37},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQ~KBNR w KQkq - 0 1", Standard, 40},
+	}
+
+	for _, test := range tests {
+		_, err := ParseVariantFEN(test.fen, test.variant)
+
+		var fenError *FENError
+		if !errors.As(err, &fenError) {
+			t.Errorf("expected FEN error for %s: %v", test.fen, err)
+			continue
+		}
+		if fenError.Column != test.column {
+			t.Errorf("unexpected error column for %s: expected=%d; actual=%d (%s)", test.fen, test.column, fenError.Column, fenError.Message)
+		}
+	}
+}
+
+func TestDropMoveCounts(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected int
+	}{
+		// Five king moves and a knight drop on every empty square
+		{"4k3/8/8/8/8/8/8/4K3[N] w - - 0 1", 67},
+		// Pawns cannot be dropped on the first or last rank
+		{"4k3/8/8/8/8/8/8/4K3[P] w - - 0 1", 53},
+		// Only the pieces in the active color's pocket can be dropped
+		{"4k3/8/8/8/8/8/8/4K3[n] w - - 0 1", 5},
+		// Drops can block a check
+		{"4k3/8/8/8/8/8/8/r3K3[N] w - - 0 1", 6},
+		{"4k3/8/8/8/8/8/8/r3K3[P] w - - 0 1", 3},
+		// No drop can stop a double check or a check by a knight
+		{"4k3/8/8/8/8/3n4/8/r3K3[Q] w - - 0 1", 2},
+		{"4k3/8/8/8/8/5n2/8/4K3[Q] w - - 0 1", 4},
+	}
+
+	for _, test := range tests {
+		state, err := ParseVariantFEN(test.fen, Crazyhouse)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		moves, err := state.GenerateAllMoves()
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if len(moves) != test.expected {
+			t.Errorf("incorrect number of moves for %s: expected=%d; actual=%d", test.fen, test.expected, len(moves))
+		}
+	}
+}
+
+func TestCrazyhouseMoveCount(t *testing.T) {
+	state, err := ParseVariantFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1", Crazyhouse)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The first drops are made on the fifth ply
+	testMoveCount(t, state, 4888832, 5)
+}
+
+func TestCapturedPiecesGoToPocket(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     Move
+		expected string
+	}{
+		// Captured pieces change color
+		{"4k3/8/8/8/8/8/8/4Kq2[] w - - 0 1", Move{Start: Position{7, 4}, End: Position{7, 5}}, "4k3/8/8/8/8/8/8/5K2[Q] b - - 0 1"},
+		// A captured promoted piece returns to the pocket as a pawn
+		{"4k3/8/8/8/8/8/6q1/4K2Q~[r] b - - 0 1", Move{Start: Position{6, 6}, End: Position{7, 7}}, "4k3/8/8/8/8/8/8/4K2q[rp] w - - 0 2"},
+		// En passant captures a pawn
+		{"4k3/8/8/3Pp3/8/8/8/4K3[] w - e6 0 2", Move{Start: Position{3, 3}, End: Position{2, 4}, Flag: EnPassant}, "4k3/8/4P3/8/8/8/8/4K3[P] b - - 0 2"},
+	}
+
+	for _, test := range tests {
+		state, err := ParseVariantFEN(test.fen, Crazyhouse)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		undo := state.DoMove(test.move)
+		if state.FEN() != test.expected {
+			t.Errorf("incorrect state after capturing in %s: expected=%s; actual=%s", test.fen, test.expected, state.FEN())
+		}
+
+		state.UndoMove(undo)
+		if state.FEN() != test.fen {
+			t.Errorf("undoing the capture did not restore %s: %s", test.fen, state.FEN())
+		}
+	}
+}
+
+func TestPromotedPiecesAreTracked(t *testing.T) {
+	state, err := ParseVariantFEN("4k3/P7/8/8/8/8/8/4K3[] w - - 0 1", Crazyhouse)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, notation := range []AlgebraicNotation{"a8=Q+", "Kd7", "Qb7+"} {
+		doStateAlgebraicMove(t, &state, string(notation))
+	}
+
+	expected := "8/1Q~1k4/8/8/8/8/8/4K3[] b - - 2 2"
+	if state.FEN() != expected {
+		t.Errorf("incorrect FEN after moving a promoted queen: expected=%s; actual=%s", expected, state.FEN())
+	}
+}
+
+func TestDropNotation(t *testing.T) {
+	state := InitialiseState()
+	state.SetVariant(Crazyhouse)
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, algebraic := range []AlgebraicNotation{"e4", "d5", "exd5", "Qxd5", "Nc3", "Qa5"} {
+		doAlgebraicMove(t, &game, algebraic)
+	}
+
+	_, err = AlgebraicNotation("N@f6").ToMove(game.State)
+	if !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("expected a knight drop without a knight in the pocket to be illegal: %v", err)
+	}
+
+	move, err := AlgebraicNotation("@e4").ToMove(game.State)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if move != (Move{Position{4, 4}, Position{4, 4}, Drop, EmptySquare, WhitePawn}) {
+		t.Errorf("incorrect drop: %+v", move)
+	}
+
+	algebraic, err := move.ToAlgebraicNotation(game.State)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if algebraic != "P@e4" || move.String() != "P@e4" {
+		t.Errorf("incorrect notation for a pawn drop: SAN=%s; UCI=%s", algebraic, move.String())
+	}
+
+	doAlgebraicMove(t, &game, "P@e4")
+	if err := game.DoUCIMove("P@e2"); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.State.Pockets != (Pockets{}) {
+		t.Errorf("dropped pieces were not removed from the pockets: %+v", game.State.Pockets)
+	}
+
+	if err := game.DoUCIMove("P@e3"); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("expected a drop from an empty pocket to be illegal: %v", err)
+	}
+	if err := game.DoUCIMove("K@e3"); !errors.Is(err, ErrMalformedMove) {
+		t.Errorf("expected a king drop to be malformed: %v", err)
+	}
+}
+
+func TestCrazyhouseHashAndStages(t *testing.T) {
+	state, err := ParseVariantFEN(crazyhouseTestFEN, Crazyhouse)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	testHashAfterMoves(t, state, 2)
+	testStagedMoveGeneration(t, state, 2)
+}
@@ -6,108 +6,223 @@ import (
 	"strings"
 )
 
-func fenToState(fenString string) (State, error) {
-	// Read and validate tokens
-	tokens := strings.Split(fenString, " ")
-	if len(tokens) != 6 {
-		return State{}, fmt.Errorf("incorrect number of tokens in FEN state: %s", fenString)
-	}
+// FENError describes why a FEN string could not be parsed, along with where the problem was found.
+type FENError struct {
+	FEN     string
+	Column  int
+	Message string
+}
 
-	boardRanks := strings.Split(tokens[0], "/")
-	if len(boardRanks) != 8 {
-		return State{}, fmt.Errorf("incorrect number of ranks in FEN state: %s", fenString)
-	}
+func (err *FENError) Error() string {
+	return fmt.Sprintf("invalid FEN at column %d: %s: %s", err.Column, err.Message, err.FEN)
+}
 
-	color := tokens[1]
-	if color != "w" && color != "b" {
-		return State{}, fmt.Errorf("invalid active color specified in FEN state: %s", fenString)
-	}
+// fenToken is a space separated field of a FEN string along with its 1-based starting column.
+type fenToken struct {
+	value  string
+	column int
+}
+
+// ParseFEN converts a string in Forsyth-Edwards Notation into a State, including the halfmove clock and fullmove number.
+func ParseFEN(fenString string) (State, error) {
+	return ParseVariantFEN(fenString, Standard)
+}
+
+// ParseVariantFEN converts a FEN string into a State played under the given variant.
+// Variants which count checks accept the checks given by each side as a seventh field, such as +1+0 after white has given one check.
+// Variants with pockets accept the pieces in the pockets in brackets after the board, such as [Nbp], with promoted pieces followed by a ~.
+func ParseVariantFEN(fenString string, variant Variant) (State, error) {
+	return parseFEN(fenString, variant, false)
+}
+
+// ParseChess960FEN converts a FEN string into a Chess960 State played under the given variant,
+// reading KQkq as the outermost rooks either side of the king, as in the X-FEN written for Chess960 starting positions.
+func ParseChess960FEN(fenString string, variant Variant) (State, error) {
+	return parseFEN(fenString, variant, true)
+}
 
-	castlingRights := tokens[2]
-	if len(castlingRights) > 4 {
-		return State{}, fmt.Errorf("too many characters specifying castling rights in FEN state: %s", fenString)
+// parseFEN converts a FEN string into a State, reading KQkq as Chess960 castling rights if isChess960 is true
+// or if the files of any castling rooks are given.
+func parseFEN(fenString string, variant Variant, isChess960 bool) (State, error) {
+	fail := func(column int, format string, args ...any) (State, error) {
+		return State{}, &FENError{fenString, column, fmt.Sprintf(format, args...)}
 	}
 
-	for _, char := range castlingRights {
-		if char != '-' && char != 'K' && char != 'Q' && char != 'k' && char != 'q' {
-			return State{}, fmt.Errorf("invalid character specifying castling rights in FEN state: %s", fenString)
+	rules := variant.Rules()
+
+	// Read and validate tokens
+	tokens := []fenToken{}
+	column := 1
+	for _, value := range strings.Split(fenString, " ") {
+		if value == "" {
+			return fail(column, "empty field")
 		}
+		tokens = append(tokens, fenToken{value, column})
+		column += len(value) + 1
 	}
-
-	enPassant := tokens[3]
-	if len(enPassant) > 2 {
-		return State{}, fmt.Errorf("too many characters specifying en passant target square in FEN state: %s", fenString)
+	if len(tokens) != 6 && !(len(tokens) == 7 && rules.CountChecks) {
+		return fail(1, "expected 6 fields but found %d", len(tokens))
 	}
 
-	// Half moves
-	_, err := strconv.Atoi(tokens[4])
-	if err != nil {
-		return State{}, err
-	}
+	// Split off the pockets
+	boardToken := tokens[0]
+	var pocketToken fenToken
+	if open := strings.IndexByte(boardToken.value, '['); open >= 0 {
+		if !rules.Drops {
+			return fail(boardToken.column+open, "pockets are not used in %s", variant.Name())
+		}
+		if !strings.HasSuffix(boardToken.value, "]") {
+			return fail(boardToken.column+len(boardToken.value)-1, "pockets are not closed by ]")
+		}
 
-	// Full moves
-	_, err = strconv.Atoi(tokens[5])
-	if err != nil {
-		return State{}, err
+		pocketToken = fenToken{boardToken.value[open+1 : len(boardToken.value)-1], boardToken.column + open + 1}
+		boardToken.value = boardToken.value[:open]
 	}
 
 	// Convert board
 	board := Board{}
-	for rankIndex, rank := range boardRanks {
-		fileIndex := 0
-		for _, char := range rank {
-			// Handle number of empty squares
-			if n, err := strconv.Atoi(string(char)); err == nil {
-				for c := 0; c < n; c++ {
-					board[rankIndex][fileIndex] = EmptySquare
-					fileIndex++
-				}
-			} else {
-				piece, err := fenPieceToPiece(char)
-				if err != nil {
-					return State{}, err
-				}
+	var promoted Bitboard
+	rankIndex := 0
+	fileIndex := 0
+	for i, char := range boardToken.value {
+		column := boardToken.column + i
 
-				board[rankIndex][fileIndex] = piece
-				fileIndex++
+		switch {
+		case char == '~':
+			// Marks the previous piece as promoted
+			if !rules.Drops || i == 0 || fileIndex == 0 || strings.IndexByte("12345678/~", boardToken.value[i-1]) >= 0 {
+				return fail(column, "~ must follow a piece in a variant with pockets")
+			}
+			promoted |= PositionBitboard(Position{int8(rankIndex), int8(fileIndex - 1)})
+		case char == '/':
+			if fileIndex != 8 {
+				return fail(column, "rank %d has %d files", 8-rankIndex, fileIndex)
+			}
+			rankIndex++
+			fileIndex = 0
+			if rankIndex > 7 {
+				return fail(column, "too many ranks")
+			}
+		case char >= '1' && char <= '8':
+			// Handle number of empty squares
+			n := int(char - '0')
+			if fileIndex+n > 8 {
+				return fail(column, "rank %d has too many files", 8-rankIndex)
+			}
+			fileIndex += n
+		default:
+			piece, err := fenPieceToPiece(char)
+			if err != nil {
+				return fail(column, "%s", err)
 			}
+			if fileIndex > 7 {
+				return fail(column, "rank %d has too many files", 8-rankIndex)
+			}
+
+			board.SetSquare(Position{int8(rankIndex), int8(fileIndex)}, piece)
+			fileIndex++
 		}
 	}
+	if rankIndex != 7 {
+		return fail(boardToken.column+len(boardToken.value), "expected 8 ranks but found %d", rankIndex+1)
+	}
+	if fileIndex != 8 {
+		return fail(boardToken.column+len(boardToken.value), "rank 1 has %d files", fileIndex)
+	}
+
+	// Pockets
+	pockets := Pockets{}
+	for i, char := range pocketToken.value {
+		piece, err := fenPieceToPiece(char)
+		if err != nil || piece == WhiteKing || piece == BlackKing {
+			return fail(pocketToken.column+i, "invalid pocket piece %q", char)
+		}
+		pockets.add(piece)
+	}
 
 	// Active color
 	var activeColor Color
-	if color == "w" {
+	switch tokens[1].value {
+	case "w":
 		activeColor = White
-	} else if color == "b" {
+	case "b":
 		activeColor = Black
+	default:
+		return fail(tokens[1].column, "invalid active color %q", tokens[1].value)
+	}
+
+	// Castling rights, given either as KQkq or as the files of the castling rooks for Chess960 (X-FEN and Shredder-FEN).
+	// KQkq refer to the king on the e file and the rooks on the a and h files unless the position is read as Chess960,
+	// in which case they refer to the outermost rooks either side of the king.
+	castlingRights := CastlingRights{}
+	castlingRookFiles := standardCastlingRookFiles
+	castlingKingFiles := standardCastlingKingFiles
+	if tokens[2].value != "-" && rules.OrdinaryKing {
+		return fail(tokens[2].column, "castling is not allowed in %s", variant.Name())
 	}
+	if tokens[2].value != "-" {
+		isChess960 = isChess960 || strings.ContainsAny(tokens[2].value, "ABCDEFGHabcdefgh")
+		for i, char := range tokens[2].value {
+			column := tokens[2].column + i
+
+			color := White
+			if char >= 'a' && char <= 'z' {
+				color = Black
+				char -= 'a' - 'A'
+			}
+
+			kingFile := standardCastlingKingFiles.White
+			if isChess960 {
+				var ok bool
+				kingFile, ok = board.getCastlingKingFile(color)
+				if !ok {
+					return fail(column, "castling right %q has no king on the back rank", tokens[2].value[i])
+				}
+			}
+
+			var kingSide bool
+			var rookFile int8
+			switch {
+			case char == 'K':
+				kingSide = true
+				rookFile = standardCastlingRookFiles.WhiteKingSide
+				if isChess960 {
+					rookFile = board.getOutermostRookFile(color, kingSide, kingFile)
+				}
+			case char == 'Q':
+				kingSide = false
+				rookFile = standardCastlingRookFiles.WhiteQueenSide
+				if isChess960 {
+					rookFile = board.getOutermostRookFile(color, kingSide, kingFile)
+				}
+			case char >= 'A' && char <= 'H':
+				rookFile = int8(char - 'A')
+				if rookFile == kingFile {
+					return fail(column, "castling rook cannot be on the same file as the king")
+				}
+				kingSide = rookFile > kingFile
+			default:
+				return fail(column, "invalid castling right %q", tokens[2].value[i])
+			}
 
-	// Castling rights
-	whiteCanCastleQueenSide := false
-	whiteCanCastleKingSide := false
-	blackCanCastleQueenSide := false
-	blackCanCastleKingSide := false
-	for _, char := range castlingRights {
-		switch char {
-		case 'K':
-			whiteCanCastleKingSide = true
-		case 'Q':
-			whiteCanCastleQueenSide = true
-		case 'k':
-			blackCanCastleKingSide = true
-		case 'q':
-			blackCanCastleQueenSide = true
+			if castlingRights.CanCastle(color, kingSide) {
+				return fail(column, "duplicate castling right %q", tokens[2].value[i])
+			}
+			castlingRights.setCastlingRight(color, kingSide, true)
+			castlingRookFiles.setCastlingRookFile(color, kingSide, rookFile)
+			castlingKingFiles.setCastlingKingFile(color, kingFile)
 		}
 	}
 
 	// En passant
-	var enPassantSquare PositionOpt
-	if enPassant == "-" {
-		enPassantSquare = PositionOpt{Ok: false}
-	} else {
-		pos, err := stringToPosition(enPassant)
+	enPassantSquare := PositionOpt{Ok: false}
+	if tokens[3].value != "-" {
+		pos, err := stringToPosition(tokens[3].value)
 		if err != nil {
-			return State{}, err
+			return fail(tokens[3].column, "invalid en passant square %q", tokens[3].value)
+		}
+		if pos.X != 2 && pos.X != 5 {
+			return fail(tokens[3].column, "en passant square %q is not on the third or sixth rank", tokens[3].value)
 		}
 
 		enPassantSquare = PositionOpt{
@@ -116,17 +231,211 @@ func fenToState(fenString string) (State, error) {
 		}
 	}
 
-	return State{
+	// Half moves
+	halfMoveClock, err := strconv.Atoi(tokens[4].value)
+	if err != nil || halfMoveClock < 0 {
+		return fail(tokens[4].column, "invalid halfmove clock %q", tokens[4].value)
+	}
+
+	// Full moves
+	fullMoveNumber, err := strconv.Atoi(tokens[5].value)
+	if err != nil || fullMoveNumber < 1 {
+		return fail(tokens[5].column, "invalid fullmove number %q", tokens[5].value)
+	}
+
+	// Checks given
+	checks := CheckCounts{}
+	if len(tokens) == 7 {
+		var ok bool
+		checks, ok = parseCheckCounts(tokens[6].value)
+		if !ok {
+			return fail(tokens[6].column, "invalid check counts %q", tokens[6].value)
+		}
+	}
+
+	state := State{
 		board,
-		CastlingRights{
-			whiteCanCastleKingSide,
-			whiteCanCastleQueenSide,
-			blackCanCastleKingSide,
-			blackCanCastleQueenSide,
-		},
+		castlingRights,
+		castlingRookFiles,
+		castlingKingFiles,
 		activeColor,
 		enPassantSquare,
-	}, nil
+		halfMoveClock,
+		fullMoveNumber,
+		variant,
+		checks,
+		pockets,
+		promoted,
+		0,
+	}
+	state.Hash = state.ComputeHash()
+
+	return state, nil
+}
+
+// FEN converts the state into a string in Forsyth-Edwards Notation.
+// Castling rights are written as KQkq when the king and rook start on their standard squares, and otherwise as the file of the rook,
+// which X-FEN readers treat as a Chess960 position.
+func (state *State) FEN() string {
+	return state.toFEN(false)
+}
+
+// ShredderFEN converts the state into a string in Shredder-FEN, where castling rights are always given by the files of the castling rooks.
+func (state *State) ShredderFEN() string {
+	return state.toFEN(true)
+}
+
+// toFEN converts the state into a FEN string, using the files of the castling rooks as castling rights if shredder is true.
+func (state *State) toFEN(shredder bool) string {
+	var builder strings.Builder
+	rules := state.GetVariant().Rules()
+
+	// Board
+	for i := int8(0); i < 8; i++ {
+		if i > 0 {
+			builder.WriteByte('/')
+		}
+
+		emptySquares := 0
+		for j := int8(0); j < 8; j++ {
+			piece := state.Board.GetSquare(Position{i, j})
+			if piece == EmptySquare {
+				emptySquares++
+				continue
+			}
+
+			if emptySquares > 0 {
+				builder.WriteString(strconv.Itoa(emptySquares))
+				emptySquares = 0
+			}
+			builder.WriteRune(pieceToFENPiece(piece))
+			if state.Promoted.Contains(Position{i, j}) {
+				builder.WriteByte('~')
+			}
+		}
+		if emptySquares > 0 {
+			builder.WriteString(strconv.Itoa(emptySquares))
+		}
+	}
+
+	// Pockets
+	if rules.Drops {
+		builder.WriteString("[" + state.Pockets.String() + "]")
+	}
+
+	// Active color
+	if state.ActiveColor == White {
+		builder.WriteString(" w ")
+	} else {
+		builder.WriteString(" b ")
+	}
+
+	// Castling rights, using the rook's file in place of K or Q when the king or rook is not on its standard square
+	castlingRights := ""
+	standard := State{CastlingRookFiles: standardCastlingRookFiles, CastlingKingFiles: standardCastlingKingFiles}
+	for _, color := range [2]Color{White, Black} {
+		for _, kingSide := range [2]bool{true, false} {
+			if !state.CastlingRights.CanCastle(color, kingSide) {
+				continue
+			}
+
+			rookPosition := state.CastlingRookPosition(color, kingSide)
+			var char byte
+			if shredder ||
+				rookPosition != standard.CastlingRookPosition(color, kingSide) ||
+				state.CastlingKingPosition(color) != standard.CastlingKingPosition(color) {
+
+				char = 'A' + byte(rookPosition.Y)
+			} else if kingSide {
+				char = 'K'
+			} else {
+				char = 'Q'
+			}
+
+			if color == Black {
+				char += 'a' - 'A'
+			}
+			castlingRights += string(char)
+		}
+	}
+	if castlingRights == "" {
+		castlingRights = "-"
+	}
+	builder.WriteString(castlingRights)
+
+	// En passant
+	builder.WriteByte(' ')
+	enPassant, err := positionToString(state.EnPassantPosition.Position)
+	if !state.EnPassantPosition.Ok || err != nil {
+		enPassant = "-"
+	}
+	builder.WriteString(enPassant)
+
+	// Clocks
+	builder.WriteString(fmt.Sprintf(" %d %d", state.HalfMoveClock, state.FullMoveNumber))
+
+	// Checks given
+	if rules.CountChecks {
+		builder.WriteString(fmt.Sprintf(" +%d+%d", state.Checks.White, state.Checks.Black))
+	}
+
+	return builder.String()
+}
+
+// parseCheckCounts reads the checks given by each side in the form +N+M, where N is the number given by white.
+func parseCheckCounts(value string) (CheckCounts, bool) {
+	fields := strings.Split(value, "+")
+	if len(fields) != 3 || fields[0] != "" {
+		return CheckCounts{}, false
+	}
+
+	white, whiteErr := strconv.Atoi(fields[1])
+	black, blackErr := strconv.Atoi(fields[2])
+	if whiteErr != nil || blackErr != nil || white < 0 || black < 0 || white > threeCheckWinningChecks || black > threeCheckWinningChecks {
+		return CheckCounts{}, false
+	}
+	return CheckCounts{white, black}, true
+}
+
+// getCastlingKingFile returns the file of the given color's king, or false if it is not on its back rank.
+func (board *Board) getCastlingKingFile(color Color) (int8, bool) {
+	rank := backRank(color)
+	king := WhiteKing
+	if color == Black {
+		king = BlackKing
+	}
+
+	for file := int8(0); file < 8; file++ {
+		if board.GetSquare(Position{rank, file}) == king {
+			return file, true
+		}
+	}
+	return 0, false
+}
+
+// getOutermostRookFile returns the file of the given color's rook on its back rank which is furthest from the king on the given side.
+// The standard a or h file is returned if there is no such rook.
+func (board *Board) getOutermostRookFile(color Color, kingSide bool, kingFile int8) int8 {
+	rank := backRank(color)
+	rook := WhiteRook
+	if color == Black {
+		rook = BlackRook
+	}
+
+	file, step := int8(0), int8(1)
+	if kingSide {
+		file, step = 7, -1
+	}
+	for ; file != kingFile; file += step {
+		if board.GetSquare(Position{rank, file}) == rook {
+			return file
+		}
+	}
+
+	if kingSide {
+		return 7
+	}
+	return 0
 }
 
 func fenPieceToPiece(p rune) (Piece, error) {
@@ -157,5 +466,36 @@ func fenPieceToPiece(p rune) (Piece, error) {
 		return WhitePawn, nil
 	}
 
-	return 0, fmt.Errorf("invalid piece character in FEN state: %c", p)
+	return 0, fmt.Errorf("invalid piece character %q", p)
+}
+
+func pieceToFENPiece(piece Piece) rune {
+	switch piece {
+	case BlackRook:
+		return 'r'
+	case BlackKnight:
+		return 'n'
+	case BlackBishop:
+		return 'b'
+	case BlackQueen:
+		return 'q'
+	case BlackKing:
+		return 'k'
+	case BlackPawn:
+		return 'p'
+	case WhiteRook:
+		return 'R'
+	case WhiteKnight:
+		return 'N'
+	case WhiteBishop:
+		return 'B'
+	case WhiteQueen:
+		return 'Q'
+	case WhiteKing:
+		return 'K'
+	case WhitePawn:
+		return 'P'
+	}
+
+	return '?'
 }
@@ -0,0 +1,76 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFENRoundTrip(t *testing.T) {
+	fens, err := loadTestFENs()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, fen := range fens {
+		state, err := ParseFEN(fen)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		if state.FEN() != fen {
+			t.Errorf("FEN did not round trip: expected=%s; actual=%s", fen, state.FEN())
+		}
+	}
+}
+
+func TestInitialStateFEN(t *testing.T) {
+	state := InitialiseState()
+
+	expected := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	if state.FEN() != expected {
+		t.Errorf("incorrect FEN for the initial state: expected=%s; actual=%s", expected, state.FEN())
+	}
+
+	parsed, err := ParseFEN(expected)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if parsed != state {
+		t.Errorf("parsed FEN does not match the initial state")
+	}
+}
+
+func TestParseFENErrors(t *testing.T) {
+	tests := []struct {
+		fen    string
+		column int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0", 1},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w  KQkq - 0 1", 47},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq - 0 1", 43},
+		{"rnbqkbnr/ppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 17},
+		{"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 19},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1", 35},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", 45},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkX - 0 1", 50},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQKq - 0 1", 49},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e4 0 1", 52},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1", 54},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0", 56},
+	}
+
+	for _, test := range tests {
+		_, err := ParseFEN(test.fen)
+
+		var fenError *FENError
+		if !errors.As(err, &fenError) {
+			t.Errorf("expected FEN error for %s: %v", test.fen, err)
+			continue
+		}
+
+		if fenError.Column != test.column {
+			t.Errorf("unexpected error column for %s: expected=%d; actual=%d (%s)", test.fen, test.column, fenError.Column, fenError.Message)
+		}
+	}
+}
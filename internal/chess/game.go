@@ -1,56 +1,344 @@
 package chess
 
+import "errors"
+
+const (
+	fiftyMoveRuleHalfMoves       = 100
+	seventyFiveMoveRuleHalfMoves = 150
+	threefoldRepetitionCount     = 3
+	fivefoldRepetitionCount      = 5
+)
+
 type Game struct {
-	State         State
-	Moves         []Move
-	Checkmate     bool
-	Stalemate     bool
-	PossibleMoves []Move
+	State          State
+	Moves          []Move
+	Outcome        Outcome
+	PossibleMoves  []Move
+	positionCounts map[positionKey]int
+	history        []moveRecord
+	undoneMoves    []Move
+	// ended is set when the outcome was decided by the players rather than by the position, such as by resignation or a draw claim.
+	ended bool
 }
 
-func InitialiseGame() Game {
-	initialState := InitialiseState()
+// moveRecord holds everything needed to take back a move made in a game.
+type moveRecord struct {
+	undo          UndoRecord
+	possibleMoves []Move
+	outcome       Outcome
+	positionKey   positionKey
+}
+
+// positionKey identifies a position for the purposes of detecting repetitions.
+type positionKey struct {
+	board             Board
+	activeColor       Color
+	castlingRights    CastlingRights
+	enPassantPosition PositionOpt
+	checks            CheckCounts
+	pockets           Pockets
+	promoted          Bitboard
+}
+
+var (
+	ErrGameOver     = errors.New("the game is already over")
+	ErrNoDrawClaim  = errors.New("no draw can be claimed in the current position")
+	ErrNoMoveToUndo = errors.New("there is no move to undo")
+	ErrNoMoveToRedo = errors.New("there is no move to redo")
+)
 
-	possibleMoves, err := initialState.GenerateAllMoves()
+func InitialiseGame() Game {
+	game, err := InitialiseGameFromState(InitialiseState())
 	if err != nil {
 		panic("call to GenerateAllMoves should never fail in the opening position.")
 	}
 
-	return Game{
-		State:         initialState,
+	return game
+}
+
+// InitialiseGameFromState creates a Game which starts from the given state.
+func InitialiseGameFromState(state State) (Game, error) {
+	possibleMoves, err := state.GenerateAllMoves()
+	if err != nil {
+		return Game{}, err
+	}
+
+	game := Game{
+		State:         state,
 		Moves:         []Move{},
-		Checkmate:     false,
-		Stalemate:     false,
 		PossibleMoves: possibleMoves,
+		positionCounts: map[positionKey]int{
+			getPositionKey(state, possibleMoves): 1,
+		},
 	}
+
+	game.Outcome, err = game.getAutomaticOutcome()
+	if err != nil {
+		return Game{}, err
+	}
+
+	return game, nil
 }
 
-// DoMove takes in a Game object and a Move and executes the move, returning the updated Game object.
+// DoMove executes the move in the game, updating its state, moves and outcome.
+// The move must match one of the PossibleMoves by its start, end and flag, and by its piece for drops, otherwise a *MoveError is returned and the game is unchanged.
+// Any moves which were previously undone can no longer be redone.
 func (game *Game) DoMove(move Move) error {
-	game.State.DoMove(move)
-	game.Moves = append(game.Moves, move)
+	if game.Outcome.IsOver() {
+		return &MoveError{move.String(), ErrGameOver}
+	}
+
+	for _, legalMove := range game.PossibleMoves {
+		if legalMove.Start == move.Start && legalMove.End == move.End && legalMove.Flag == move.Flag && (!move.IsDrop() || legalMove.Piece == move.Piece) {
+			return game.applyMove(legalMove)
+		}
+	}
+
+	piece := game.State.Board.GetSquare(move.Start)
+	if move.IsDrop() {
+		piece = move.Piece
+	}
+	if piece != EmptySquare && pieceColor(piece) != game.State.ActiveColor {
+		return &MoveError{move.String(), ErrWrongSide}
+	}
+	return &MoveError{move.String(), ErrIllegalMove}
+}
+
+// DoAlgebraicMove executes a move given in standard algebraic notation, such as Nf3, exd5 or e8=Q.
+// A *MoveError is returned if the notation is malformed, ambiguous or not a legal move for the side to move.
+func (game *Game) DoAlgebraicMove(algebraic AlgebraicNotation) error {
+	if game.Outcome.IsOver() {
+		return &MoveError{string(algebraic), ErrGameOver}
+	}
+
+	move, err := algebraic.resolve(game.State, game.PossibleMoves)
+	if err != nil {
+		return err
+	}
+	return game.applyMove(move)
+}
+
+// DoUCIMove executes a move given in the coordinate notation used by UCI, such as e2e4 or e7e8q.
+// A *MoveError is returned if the notation is malformed or not a legal move for the side to move.
+func (game *Game) DoUCIMove(notation UCINotation) error {
+	if game.Outcome.IsOver() {
+		return &MoveError{string(notation), ErrGameOver}
+	}
+
+	move, err := game.State.parseUCIMove(string(notation), game.PossibleMoves)
+	if err != nil {
+		return err
+	}
+	return game.applyMove(move)
+}
+
+// applyMove executes a legal move, after which any moves which were previously undone can no longer be redone.
+func (game *Game) applyMove(move Move) error {
+	game.undoneMoves = game.undoneMoves[:0]
+	return game.doMove(move)
+}
+
+// UndoMove takes back the last move made in the game, restoring the previous state and outcome.
+// A game ended by resignation, abandonment, timeout, agreement or a draw claim cannot be taken back, so ErrGameOver is returned.
+func (game *Game) UndoMove() error {
+	if game.ended {
+		return ErrGameOver
+	}
+	if len(game.history) == 0 {
+		return ErrNoMoveToUndo
+	}
+
+	record := game.history[len(game.history)-1]
+	game.history = game.history[:len(game.history)-1]
+
+	game.positionCounts[record.positionKey]--
+	if game.positionCounts[record.positionKey] == 0 {
+		delete(game.positionCounts, record.positionKey)
+	}
+
+	game.State.UndoMove(record.undo)
+	game.Moves = game.Moves[:len(game.Moves)-1]
+	game.PossibleMoves = record.possibleMoves
+	game.Outcome = record.outcome
+	game.undoneMoves = append(game.undoneMoves, record.undo.Move)
+
+	return nil
+}
+
+// RedoMove replays the last move taken back by UndoMove.
+// ErrGameOver is returned if the game has ended since the move was taken back.
+func (game *Game) RedoMove() error {
+	if game.Outcome.IsOver() {
+		return ErrGameOver
+	}
+	if len(game.undoneMoves) == 0 {
+		return ErrNoMoveToRedo
+	}
+
+	move := game.undoneMoves[len(game.undoneMoves)-1]
+	game.undoneMoves = game.undoneMoves[:len(game.undoneMoves)-1]
+
+	return game.doMove(move)
+}
+
+// CanUndo returns whether there is a move which can be taken back.
+func (game *Game) CanUndo() bool {
+	return len(game.history) > 0
+}
+
+// CanRedo returns whether there is an undone move which can be replayed.
+func (game *Game) CanRedo() bool {
+	return len(game.undoneMoves) > 0
+}
+
+// InitialState returns the state the game started from, found by taking back every move in Moves.
+func (game *Game) InitialState() State {
+	state := game.State
+	for i := len(game.history) - 1; i >= 0; i-- {
+		state.UndoMove(game.history[i].undo)
+	}
+	return state
+}
+
+// doMove executes a move and records the information required to undo it.
+func (game *Game) doMove(move Move) error {
+	record := moveRecord{
+		undo:          game.State.DoMove(move),
+		possibleMoves: game.PossibleMoves,
+		outcome:       game.Outcome,
+	}
+	game.Moves = append(game.Moves, record.undo.Move)
 
 	possibleMoves, err := game.State.GenerateAllMoves()
 	if err != nil {
+		game.State.UndoMove(record.undo)
+		game.Moves = game.Moves[:len(game.Moves)-1]
 		return err
 	}
+	game.PossibleMoves = possibleMoves
+
+	record.positionKey = getPositionKey(game.State, possibleMoves)
+	game.positionCounts[record.positionKey]++
+	game.history = append(game.history, record)
+
+	game.Outcome, err = game.getAutomaticOutcome()
+	return err
+}
+
+// Resign ends the game with a win for the opponent of the resigning color.
+func (game *Game) Resign(color Color) error {
+	return game.end(winFor(!color, Resignation))
+}
+
+// Abandon ends the game with a win for the opponent of the color which abandoned the game.
+func (game *Game) Abandon(color Color) error {
+	return game.end(winFor(!color, Abandonment))
+}
+
+// Timeout ends the game when the given color runs out of time.
+// The game is drawn if the opponent does not have the material to checkmate.
+func (game *Game) Timeout(color Color) error {
+	if game.State.GetVariant().HasInsufficientMaterialToWin(&game.State, !color) {
+		return game.end(Outcome{Draw, Timeout})
+	}
+	return game.end(winFor(!color, Timeout))
+}
+
+// AgreeDraw ends the game as a draw by agreement.
+func (game *Game) AgreeDraw() error {
+	return game.end(Outcome{Draw, Agreement})
+}
+
+// ClaimDraw ends the game as a draw if either the threefold repetition or fifty-move rule can be claimed.
+func (game *Game) ClaimDraw() error {
+	if game.CanClaimThreefoldRepetition() {
+		return game.end(Outcome{Draw, ThreefoldRepetition})
+	} else if game.CanClaimFiftyMoveRule() {
+		return game.end(Outcome{Draw, FiftyMoveRule})
+	}
+	return ErrNoDrawClaim
+}
 
-	checkmate := false
-	stalemate := false
-	if len(possibleMoves) == 0 {
-		isInCheck, err := game.State.Board.isInCheck(game.State.ActiveColor)
+// end sets the outcome of a game which is still in progress, after which no move can be undone or redone.
+func (game *Game) end(outcome Outcome) error {
+	if game.Outcome.IsOver() {
+		return ErrGameOver
+	}
+
+	game.Outcome = outcome
+	game.ended = true
+	game.undoneMoves = game.undoneMoves[:0]
+	return nil
+}
+
+// getAutomaticOutcome returns the outcome of the current position for the rules which end the game without a claim.
+func (game *Game) getAutomaticOutcome() (Outcome, error) {
+	variant := game.State.GetVariant()
+	if outcome := variant.Outcome(&game.State, game.PossibleMoves); outcome.IsOver() {
+		return outcome, nil
+	}
+
+	if len(game.PossibleMoves) == 0 {
+		isInCheck, err := game.State.IsInCheck(game.State.ActiveColor)
 		if err != nil {
-			return err
+			return Outcome{}, err
 		}
 
 		if isInCheck {
-			checkmate = true
-		} else {
-			stalemate = false
+			return winFor(!game.State.ActiveColor, Checkmate), nil
 		}
+		return Outcome{Draw, Stalemate}, nil
 	}
 
-	game.Checkmate = checkmate
-	game.Stalemate = stalemate
-	return nil
+	if variant.HasInsufficientMaterialToWin(&game.State, White) && variant.HasInsufficientMaterialToWin(&game.State, Black) {
+		return Outcome{Draw, InsufficientMaterial}, nil
+	}
+
+	// Checked after checkmate as a checkmate delivered on the final move takes precedence over the seventy-five-move rule
+	if game.State.HalfMoveClock >= seventyFiveMoveRuleHalfMoves {
+		return Outcome{Draw, SeventyFiveMoveRule}, nil
+	}
+
+	if game.RepetitionCount() >= fivefoldRepetitionCount {
+		return Outcome{Draw, FivefoldRepetition}, nil
+	}
+
+	return Outcome{Ongoing, Unterminated}, nil
+}
+
+// CanClaimFiftyMoveRule returns whether the player to move may claim a draw by the fifty-move rule.
+func (game *Game) CanClaimFiftyMoveRule() bool {
+	return game.State.HalfMoveClock >= fiftyMoveRuleHalfMoves
+}
+
+// CanClaimThreefoldRepetition returns whether the player to move may claim a draw because the current position has occurred at least three times.
+func (game *Game) CanClaimThreefoldRepetition() bool {
+	return game.RepetitionCount() >= threefoldRepetitionCount
+}
+
+// RepetitionCount returns the number of times the current position has occurred in the game.
+func (game *Game) RepetitionCount() int {
+	return game.positionCounts[getPositionKey(game.State, game.PossibleMoves)]
+}
+
+// getPositionKey returns the key of a state given its legal moves.
+// The en passant square is only part of the key if an en passant capture is actually possible.
+func getPositionKey(state State, possibleMoves []Move) positionKey {
+	enPassantPosition := PositionOpt{Ok: false}
+	for _, move := range possibleMoves {
+		if move.Flag == EnPassant {
+			enPassantPosition = state.EnPassantPosition
+			break
+		}
+	}
+
+	return positionKey{
+		state.Board,
+		state.ActiveColor,
+		state.CastlingRights,
+		enPassantPosition,
+		state.Checks,
+		state.Pockets,
+		state.Promoted,
+	}
 }
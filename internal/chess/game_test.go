@@ -0,0 +1,445 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoveClocks(t *testing.T) {
+	game := InitialiseGame()
+
+	moves := []AlgebraicNotation{"e4", "Nf6", "Nf3", "Nxe4"}
+	expectedHalfMoveClocks := []int{0, 1, 2, 0}
+	expectedFullMoveNumbers := []int{1, 2, 2, 3}
+
+	for i, algebraic := range moves {
+		doAlgebraicMove(t, &game, algebraic)
+
+		if game.State.HalfMoveClock != expectedHalfMoveClocks[i] {
+			t.Errorf("incorrect halfmove clock after %s: expected=%d; actual=%d", algebraic, expectedHalfMoveClocks[i], game.State.HalfMoveClock)
+		}
+		if game.State.FullMoveNumber != expectedFullMoveNumbers[i] {
+			t.Errorf("incorrect fullmove number after %s: expected=%d; actual=%d", algebraic, expectedFullMoveNumbers[i], game.State.FullMoveNumber)
+		}
+	}
+}
+
+func TestFiftyMoveRule(t *testing.T) {
+	state, err := ParseFEN("8/8/4k3/8/8/4K3/8/R7 w - - 98 80")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game.DoMove(Move{Start: Position{7, 0}, End: Position{7, 1}, Flag: None, Captured: EmptySquare})
+	if game.CanClaimFiftyMoveRule() {
+		t.Errorf("fifty-move rule should not be claimable after 99 half moves")
+	}
+
+	game.DoMove(Move{Start: Position{2, 4}, End: Position{2, 3}, Flag: None, Captured: EmptySquare})
+	if !game.CanClaimFiftyMoveRule() {
+		t.Errorf("fifty-move rule should be claimable after 100 half moves")
+	}
+	if game.Outcome.IsOver() {
+		t.Errorf("seventy-five-move rule should not apply after 100 half moves")
+	}
+}
+
+func TestSeventyFiveMoveRule(t *testing.T) {
+	state, err := ParseFEN("8/8/4k3/8/8/4K3/8/R7 w - - 149 80")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game.DoMove(Move{Start: Position{7, 0}, End: Position{7, 1}, Flag: None, Captured: EmptySquare})
+	if game.Outcome != (Outcome{Draw, SeventyFiveMoveRule}) {
+		t.Errorf("game should end by the seventy-five-move rule after 150 half moves: %s", game.Outcome)
+	}
+}
+
+func TestSeventyFiveMoveRuleCheckmateTakesPrecedence(t *testing.T) {
+	state, err := ParseFEN("7k/8/6K1/8/8/8/8/R7 w - - 149 80")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game.DoMove(Move{Start: Position{7, 0}, End: Position{0, 0}, Flag: None, Captured: EmptySquare})
+	if game.Outcome != (Outcome{WhiteWins, Checkmate}) {
+		t.Errorf("checkmate on the final move should take precedence over the seventy-five-move rule: %s", game.Outcome)
+	}
+}
+
+func TestRepetition(t *testing.T) {
+	game := InitialiseGame()
+
+	shuffle := []AlgebraicNotation{"Nf3", "Nf6", "Ng1", "Ng8"}
+	expectedThreefold := []bool{false, false, false, false, false, false, false, true}
+	for i := 0; i < 2; i++ {
+		for j, algebraic := range shuffle {
+			doAlgebraicMove(t, &game, algebraic)
+			if game.CanClaimThreefoldRepetition() != expectedThreefold[i*len(shuffle)+j] {
+				t.Errorf("unexpected threefold repetition claim after %s in cycle %d", algebraic, i+1)
+			}
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		for _, algebraic := range shuffle {
+			if game.Outcome.IsOver() {
+				t.Fatalf("game ended by fivefold repetition too early")
+			}
+			doAlgebraicMove(t, &game, algebraic)
+		}
+	}
+
+	if game.RepetitionCount() != 5 {
+		t.Errorf("unexpected repetition count: expected=5; actual=%d", game.RepetitionCount())
+	}
+	if game.Outcome != (Outcome{Draw, FivefoldRepetition}) {
+		t.Errorf("game should end by fivefold repetition: %s", game.Outcome)
+	}
+}
+
+func TestRepetitionIgnoresImpossibleEnPassant(t *testing.T) {
+	state, err := ParseFEN("4k3/8/8/8/8/8/4P3/4K1N1 w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// After e4 the en passant square is set but no capture is possible, so the position repeats after the shuffle
+	for _, algebraic := range []AlgebraicNotation{"e4", "Kd7", "Nf3", "Ke8", "Ng1", "Kd7", "Nf3", "Ke8", "Ng1"} {
+		doAlgebraicMove(t, &game, algebraic)
+	}
+
+	if game.RepetitionCount() != 3 {
+		t.Errorf("unexpected repetition count: expected=3; actual=%d", game.RepetitionCount())
+	}
+}
+
+func doAlgebraicMove(t *testing.T, game *Game, algebraic AlgebraicNotation) {
+	if err := game.DoAlgebraicMove(algebraic); err != nil {
+		t.Fatalf(err.Error())
+	}
+}
+
+func TestInsufficientMaterialEndsGame(t *testing.T) {
+	state, err := ParseFEN("8/8/4k3/8/8/4K3/3n4/2B5 w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if game.Outcome.IsOver() {
+		t.Fatalf("game should not start as a dead position")
+	}
+
+	doAlgebraicMove(t, &game, "Bxd2")
+	if game.Outcome != (Outcome{Draw, InsufficientMaterial}) {
+		t.Errorf("game should end by insufficient material: %s", game.Outcome)
+	}
+}
+
+func TestStalemate(t *testing.T) {
+	state, err := ParseFEN("7k/8/5K2/6Q1/8/8/8/8 w - - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	doAlgebraicMove(t, &game, "Qg6")
+	if game.Outcome != (Outcome{Draw, Stalemate}) {
+		t.Errorf("game should end by stalemate: %s", game.Outcome)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected Outcome
+	}{
+		{"8/8/4k3/8/8/4K3/8/7Q w - - 0 1", Outcome{WhiteWins, Timeout}},
+		{"8/8/4k3/8/8/4K3/8/6q1 w - - 0 1", Outcome{Draw, Timeout}},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		game, err := InitialiseGameFromState(state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		err = game.Timeout(Black)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if game.Outcome != test.expected {
+			t.Errorf("unexpected outcome after timeout for %s: expected=%s; actual=%s", test.fen, test.expected, game.Outcome)
+		}
+	}
+}
+
+func TestClaimDraw(t *testing.T) {
+	game := InitialiseGame()
+
+	if err := game.ClaimDraw(); err != ErrNoDrawClaim {
+		t.Errorf("expected draw claim to be refused: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		for _, algebraic := range []AlgebraicNotation{"Nf3", "Nf6", "Ng1", "Ng8"} {
+			doAlgebraicMove(t, &game, algebraic)
+		}
+	}
+
+	if err := game.ClaimDraw(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.Outcome != (Outcome{Draw, ThreefoldRepetition}) {
+		t.Errorf("game should end by threefold repetition: %s", game.Outcome)
+	}
+
+	if err := game.Resign(White); err != ErrGameOver {
+		t.Errorf("expected resignation after the game ended to be refused: %v", err)
+	}
+}
+
+func TestUndoAndRedoMoves(t *testing.T) {
+	game := InitialiseGame()
+	initialState := game.State
+	initialMoveCount := len(game.PossibleMoves)
+
+	foolsMate := []AlgebraicNotation{"f3", "e5", "g4", "Qh4#"}
+	for _, algebraic := range foolsMate {
+		doAlgebraicMove(t, &game, algebraic)
+	}
+
+	finalState := game.State
+	if game.Outcome != (Outcome{BlackWins, Checkmate}) {
+		t.Fatalf("expected checkmate: %s", game.Outcome)
+	}
+	if state := game.InitialState(); state != initialState {
+		t.Errorf("incorrect initial state: %s", state.FEN())
+	}
+
+	if err := game.UndoMove(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.Outcome.IsOver() {
+		t.Errorf("outcome was not restored after undo: %s", game.Outcome)
+	}
+	if len(game.PossibleMoves) == 0 {
+		t.Errorf("possible moves were not restored after undo")
+	}
+
+	for game.CanUndo() {
+		if err := game.UndoMove(); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	if game.State != initialState {
+		t.Errorf("incorrect state after undoing all moves\nexpected=\n%s\nactual=\n%s", BoardToDisplayString(initialState.Board), BoardToDisplayString(game.State.Board))
+	}
+	if len(game.Moves) != 0 || len(game.PossibleMoves) != initialMoveCount {
+		t.Errorf("moves were not restored after undoing all moves")
+	}
+	if err := game.UndoMove(); err != ErrNoMoveToUndo {
+		t.Errorf("expected undo with no moves to fail: %v", err)
+	}
+
+	for game.CanRedo() {
+		if err := game.RedoMove(); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	if game.State != finalState || len(game.Moves) != len(foolsMate) {
+		t.Errorf("incorrect state after redoing all moves")
+	}
+	if game.Outcome != (Outcome{BlackWins, Checkmate}) {
+		t.Errorf("expected checkmate after redoing all moves: %s", game.Outcome)
+	}
+}
+
+func TestUndoAndRedoAfterResignation(t *testing.T) {
+	game := InitialiseGame()
+	for _, algebraic := range []AlgebraicNotation{"e4", "e5"} {
+		doAlgebraicMove(t, &game, algebraic)
+	}
+
+	if err := game.UndoMove(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := game.Resign(Black); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	resignation := Outcome{WhiteWins, Resignation}
+	if game.CanRedo() {
+		t.Errorf("redo should not be possible after the game ended")
+	}
+	if err := game.RedoMove(); err != ErrGameOver {
+		t.Errorf("expected redo after resignation to be refused: %v", err)
+	}
+	if err := game.UndoMove(); err != ErrGameOver {
+		t.Errorf("expected undo after resignation to be refused: %v", err)
+	}
+	if game.Outcome != resignation || len(game.Moves) != 1 {
+		t.Errorf("the resignation was not kept: %s after %d moves", game.Outcome, len(game.Moves))
+	}
+}
+
+func TestUndoRestoresRepetitionCount(t *testing.T) {
+	game := InitialiseGame()
+
+	for _, algebraic := range []AlgebraicNotation{"Nf3", "Nf6", "Ng1", "Ng8"} {
+		doAlgebraicMove(t, &game, algebraic)
+	}
+	if game.RepetitionCount() != 2 {
+		t.Fatalf("unexpected repetition count: expected=2; actual=%d", game.RepetitionCount())
+	}
+
+	game.UndoMove()
+	doAlgebraicMove(t, &game, "Nh5")
+	if game.CanRedo() {
+		t.Errorf("redo should not be possible after a new move")
+	}
+
+	game.UndoMove()
+	game.RedoMove()
+	game.UndoMove()
+	doAlgebraicMove(t, &game, "Ng8")
+	if game.RepetitionCount() != 2 {
+		t.Errorf("unexpected repetition count: expected=2; actual=%d", game.RepetitionCount())
+	}
+}
+
+func TestMoveValidation(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     string
+		uci      bool
+		expected error
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e5", false, ErrWrongSide},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e7e5", true, ErrWrongSide},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e5", true, ErrMalformedMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e4x", true, ErrMalformedMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e5", true, ErrIllegalMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Ke2", false, ErrIllegalMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Zf3", false, ErrMalformedMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e9", false, ErrMalformedMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "", false, ErrMalformedMove},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Nf3!?", false, nil},
+		{"4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nd2", false, ErrAmbiguousMove},
+		{"4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nbd2", false, nil},
+		{"4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "N1d2", false, ErrAmbiguousMove},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8", false, ErrAmbiguousMove},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=N", false, nil},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8Q+", false, nil},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8", true, ErrMalformedMove},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8r", true, nil},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0-0", false, nil},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", true, nil},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1a1", true, nil},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", "a3", false, ErrGameOver},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", "a2a3", true, ErrGameOver},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		game, err := InitialiseGameFromState(state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if test.uci {
+			err = game.DoUCIMove(UCINotation(test.move))
+		} else {
+			err = game.DoAlgebraicMove(AlgebraicNotation(test.move))
+		}
+
+		if !errors.Is(err, test.expected) {
+			t.Errorf("unexpected error for %q in %s: expected=%v; actual=%v", test.move, test.fen, test.expected, err)
+		}
+
+		var moveError *MoveError
+		if test.expected != nil && (!errors.As(err, &moveError) || moveError.Move != test.move) {
+			t.Errorf("expected a *MoveError for %q but found %v", test.move, err)
+		}
+
+		expectedMoves := 1
+		if test.expected != nil {
+			expectedMoves = 0
+		}
+		if len(game.Moves) != expectedMoves {
+			t.Errorf("expected %d moves to be made for %q but found %d", expectedMoves, test.move, len(game.Moves))
+		}
+	}
+}
+
+func TestDoMoveValidation(t *testing.T) {
+	game := InitialiseGame()
+
+	if err := game.DoMove(Move{Start: Position{6, 4}, End: Position{3, 4}}); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("expected an illegal move error but found %v", err)
+	}
+	if err := game.DoMove(Move{Start: Position{1, 4}, End: Position{3, 4}}); !errors.Is(err, ErrWrongSide) {
+		t.Errorf("expected a wrong side error but found %v", err)
+	}
+
+	// The captured piece is filled in from the matching legal move
+	for _, move := range []Move{
+		{Start: Position{6, 4}, End: Position{4, 4}},
+		{Start: Position{1, 3}, End: Position{3, 3}},
+		{Start: Position{4, 4}, End: Position{3, 3}},
+	} {
+		if err := game.DoMove(move); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	if game.Moves[2].Captured != BlackPawn {
+		t.Errorf("expected the capture to record a black pawn but found %d", game.Moves[2].Captured)
+	}
+
+	if err := game.UndoMove(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.State.Board.GetSquare(Position{3, 3}) != BlackPawn {
+		t.Errorf("captured pawn was not restored by undo")
+	}
+}
@@ -0,0 +1,53 @@
+package chess
+
+// materialCount holds the number of each type of piece belonging to one side.
+type materialCount struct {
+	pawns, knights, lightBishops, darkBishops, rooks, queens int
+}
+
+// IsInsufficientMaterial returns whether neither side can possibly checkmate, meaning the position is dead.
+func (board *Board) IsInsufficientMaterial() bool {
+	return board.HasInsufficientMaterialToWin(White) && board.HasInsufficientMaterialToWin(Black)
+}
+
+// HasInsufficientMaterialToWin returns whether the given color cannot checkmate by any sequence of legal moves.
+// This is used to decide whether a loss on time is scored as a draw instead.
+func (board *Board) HasInsufficientMaterialToWin(color Color) bool {
+	own := board.countMaterial(color)
+	enemy := board.countMaterial(!color)
+
+	if own.pawns > 0 || own.rooks > 0 || own.queens > 0 {
+		return false
+	}
+
+	ownMinors := own.knights + own.lightBishops + own.darkBishops
+	enemyPieces := enemy.pawns + enemy.knights + enemy.lightBishops + enemy.darkBishops + enemy.rooks + enemy.queens
+	switch {
+	case ownMinors == 0:
+		// A lone king can never checkmate
+		return true
+	case own.knights == 1 && ownMinors == 1:
+		// A lone knight can only checkmate if there are enemy pieces to block the king in
+		return enemyPieces == 0
+	case own.knights == 0 && own.darkBishops == 0:
+		// Bishops which only cover light squares need enemy pieces to occupy the dark escape squares
+		return enemyPieces == enemy.lightBishops
+	case own.knights == 0 && own.lightBishops == 0:
+		return enemyPieces == enemy.darkBishops
+	}
+
+	return false
+}
+
+// countMaterial counts the pieces of the given color on the board.
+func (board *Board) countMaterial(color Color) materialCount {
+	bishops := board.Pieces(colorPiece(WhiteBishop, color))
+	return materialCount{
+		board.Pieces(colorPiece(WhitePawn, color)).Count(),
+		board.Pieces(colorPiece(WhiteKnight, color)).Count(),
+		(bishops & lightSquares).Count(),
+		(bishops &^ lightSquares).Count(),
+		board.Pieces(colorPiece(WhiteRook, color)).Count(),
+		board.Pieces(colorPiece(WhiteQueen, color)).Count(),
+	}
+}
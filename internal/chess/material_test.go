@@ -0,0 +1,62 @@
+package chess
+
+import "testing"
+
+func TestInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected bool
+	}{
+		{"8/8/4k3/8/8/4K3/8/8 w - - 0 1", true},
+		{"8/8/4k3/8/8/4K3/8/5B2 w - - 0 1", true},
+		{"8/8/4k3/8/8/4K3/8/6N1 w - - 0 1", true},
+		{"8/8/4k3/1b6/8/4K3/8/5B2 w - - 0 1", true},
+		{"2b5/8/4k3/8/8/4K3/8/3B1B2 w - - 0 1", true},
+		{"8/8/4k3/2b5/8/4K3/8/5B2 w - - 0 1", false},
+		{"8/8/4k3/8/8/4K3/8/5BN1 w - - 0 1", false},
+		{"8/8/4k3/8/8/4K3/8/4NN2 w - - 0 1", false},
+		{"8/8/4k3/8/8/4K3/8/3BB3 w - - 0 1", false},
+		{"8/8/4k3/3n4/8/4K3/8/6N1 w - - 0 1", false},
+		{"8/8/4k3/8/8/4K3/4P3/8 w - - 0 1", false},
+		{"8/8/4k3/8/8/4K3/8/7R w - - 0 1", false},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if state.Board.IsInsufficientMaterial() != test.expected {
+			t.Errorf("unexpected insufficient material result for %s: expected=%t", test.fen, test.expected)
+		}
+	}
+}
+
+func TestInsufficientMaterialToWin(t *testing.T) {
+	tests := []struct {
+		fen      string
+		color    Color
+		expected bool
+	}{
+		{"8/8/4k3/8/8/4K3/8/7Q w - - 0 1", Black, true},
+		{"8/8/4k3/8/8/4K3/8/7Q w - - 0 1", White, false},
+		{"8/8/4k3/8/8/4K3/8/6N1 w - - 0 1", White, true},
+		{"8/8/4k3/8/8/4K3/8/5N1q w - - 0 1", White, false},
+		{"8/8/4k3/8/8/4K3/8/5n1Q w - - 0 1", Black, false},
+		{"8/8/4k3/3p4/8/4K3/8/5B2 w - - 0 1", White, false},
+		{"8/8/4k3/1b6/8/4K3/8/5B2 w - - 0 1", White, true},
+		{"8/8/4k3/2b5/8/4K3/8/5B2 w - - 0 1", White, false},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if state.Board.HasInsufficientMaterialToWin(test.color) != test.expected {
+			t.Errorf("unexpected insufficient material to win result for %s: expected=%t", test.fen, test.expected)
+		}
+	}
+}
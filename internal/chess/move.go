@@ -12,46 +12,194 @@ const (
 	PromoteToRook
 	PromoteToBishop
 	PromoteToKnight
+	// Drop places Move.Piece from the pocket onto End in variants such as Crazyhouse, with Start set to End.
+	Drop
+	// PromoteToKing promotes a pawn to a king in variants such as Antichess, where the king is an ordinary piece.
+	PromoteToKing
 )
 
-const bitMask3 uint8 = 0b00000111
-const bitMask4 uint8 = 0b00001111
+// EncodedMove packs a move into 32 bits:
+//   - bits 0-5 hold the start square and bits 6-11 the end square, each indexed from 0 for a8 to 63 for h1
+//   - bits 12-15 hold the flag
+//   - bits 16-19 hold the moving piece and bits 20-23 the captured piece, each offset by 6 so that they are not negative
+//
+// MoveList stores it, so it is used throughout move generation and the perft search, and it converts to and from Move without loss.
+type EncodedMove uint32
 
-/*
-3 bits for X1
-3 bits for Y1
-3 bits for X2
-3 bits for Y2
-4 bits for flag
-*/
-type EncodedMove int16
+const (
+	encodedSquareMask EncodedMove = 0b111111
+	encodedFourBits   EncodedMove = 0b1111
+
+	encodedEndShift      = 6
+	encodedFlagShift     = 12
+	encodedPieceShift    = 16
+	encodedCapturedShift = 20
+)
 
+// Move represents a move on the board.
+// Castling moves are represented by the king capturing its own rook, so Start is the king's square and End is the rook's square.
+// Piece is the piece which moves, and Captured is the piece which is captured, if any.
 type Move struct {
 	Start, End Position
 	Flag       MoveFlag
 	Captured   Piece
+	Piece      Piece
 }
 
 // ToEncoded translates a Move struct to a more compressed encoding.
-func (move Move) ToEncoded() (encoded EncodedMove) {
-	encoded = 0
-	encoded |= EncodedMove((bitMask3 & uint8(move.Start.X)))
-	encoded |= EncodedMove((bitMask3 & uint8(move.Start.Y))) << 3
-	encoded |= EncodedMove((bitMask3 & uint8(move.End.X))) << 6
-	encoded |= EncodedMove((bitMask3 & uint8(move.End.Y))) << 9
-	encoded |= EncodedMove((bitMask4 & uint8(move.Flag))) << 12
-	return
+func (move Move) ToEncoded() EncodedMove {
+	return encodeMove(squareOf(move.Start), squareOf(move.End), move.Flag, move.Captured, move.Piece)
+}
+
+// encodeMove packs the parts of a move without building a Move, for use by the move generators.
+func encodeMove(start, end Square, flag MoveFlag, captured, piece Piece) EncodedMove {
+	return EncodedMove(start) |
+		EncodedMove(end)<<encodedEndShift |
+		EncodedMove(flag)<<encodedFlagShift |
+		EncodedMove(pieceIndex(piece))<<encodedPieceShift |
+		EncodedMove(pieceIndex(captured))<<encodedCapturedShift
+}
+
+// withFlag returns the move with its flag replaced.
+func (enc EncodedMove) withFlag(flag MoveFlag) EncodedMove {
+	return enc&^(encodedFourBits<<encodedFlagShift) | EncodedMove(flag)<<encodedFlagShift
 }
 
 // ToMove translates from a compressed encoding to a Move struct.
 func (enc EncodedMove) ToMove() Move {
 	return Move{
-		Start: Position{X: int8(enc & EncodedMove(bitMask3)), Y: int8(enc & (EncodedMove(bitMask3) << 3) >> 3)},
-		End:   Position{X: int8(enc & (EncodedMove(bitMask3) << 6) >> 6), Y: int8(enc & (EncodedMove(bitMask3) << 9) >> 9)},
-		Flag:  MoveFlag(enc & (EncodedMove(bitMask3) << 12) >> 12),
+		enc.Start(),
+		enc.End(),
+		enc.Flag(),
+		enc.Captured(),
+		enc.Piece(),
 	}
 }
 
+// Start returns the square the moving piece starts on.
+func (enc EncodedMove) Start() Position {
+	return Square(enc & encodedSquareMask).Position()
+}
+
+// End returns the square the moving piece finishes on, or the castling rook's square for castling moves.
+func (enc EncodedMove) End() Position {
+	return Square(enc >> encodedEndShift & encodedSquareMask).Position()
+}
+
+// Flag returns the flag of the move.
+func (enc EncodedMove) Flag() MoveFlag {
+	return MoveFlag(enc >> encodedFlagShift & encodedFourBits)
+}
+
+// Piece returns the piece which moves.
+func (enc EncodedMove) Piece() Piece {
+	return Piece(enc>>encodedPieceShift&encodedFourBits) - 6
+}
+
+// Captured returns the piece which is captured, or EmptySquare if there is none.
+func (enc EncodedMove) Captured() Piece {
+	return Piece(enc>>encodedCapturedShift&encodedFourBits) - 6
+}
+
+// IsCastle returns whether the move is a castling move.
+func (move Move) IsCastle() bool {
+	return move.Flag == KingSideCastle || move.Flag == QueenSideCastle
+}
+
+// IsPromotion returns whether the move promotes a pawn.
+func (move Move) IsPromotion() bool {
+	return move.Flag >= PromoteToQueen && move.Flag <= PromoteToKnight || move.Flag == PromoteToKing
+}
+
+// IsDrop returns whether the move places a piece from the pocket onto the board.
+func (move Move) IsDrop() bool {
+	return move.Flag == Drop
+}
+
+// IsCapture returns whether the move captures an enemy piece, including en passant.
+func (move Move) IsCapture() bool {
+	return move.Flag == EnPassant || (!move.IsCastle() && move.Captured != EmptySquare)
+}
+
 func MoveTouchesSquare(move Move, position Position) bool {
 	return move.Start == position || move.End == position
 }
+
+// maxMoves is more than the number of legal moves in any chess position, including the drops in Crazyhouse.
+const maxMoves = 512
+
+// MoveList is a fixed capacity list of encoded moves which can be filled by the move generators without allocating.
+type MoveList struct {
+	moves [maxMoves]EncodedMove
+	count int
+}
+
+// Len returns the number of moves in the list.
+func (list *MoveList) Len() int {
+	return list.count
+}
+
+// Get returns the move at the given index.
+func (list *MoveList) Get(index int) EncodedMove {
+	return list.moves[index]
+}
+
+// Moves returns the moves in the list, backed by the list's own storage, so it is only valid until the list is next changed.
+func (list *MoveList) Moves() []EncodedMove {
+	return list.moves[:list.count]
+}
+
+// ToSlice decodes the moves in the list into a new slice.
+func (list *MoveList) ToSlice() []Move {
+	moves := make([]Move, list.count)
+	for i, move := range list.moves[:list.count] {
+		moves[i] = move.ToMove()
+	}
+	return moves
+}
+
+// Add appends a move to the list.
+func (list *MoveList) Add(move EncodedMove) {
+	list.moves[list.count] = move
+	list.count++
+}
+
+// Clear removes every move from the list.
+func (list *MoveList) Clear() {
+	list.count = 0
+}
+
+// filter keeps only the moves for which keep returns true, preserving their order.
+func (list *MoveList) filter(keep func(Move) bool) {
+	count := 0
+	for _, move := range list.moves[:list.count] {
+		if keep(move.ToMove()) {
+			list.moves[count] = move
+			count++
+		}
+	}
+	list.count = count
+}
+
+// String returns the move in coordinate notation, such as e2e4 or e7e8q, with castling given as the king capturing its own rook.
+// Drops are given by the upper case piece and the square, such as N@f3.
+func (move Move) String() string {
+	start, err := positionToString(move.Start)
+	if err != nil {
+		return "invalid"
+	}
+	end, err := positionToString(move.End)
+	if err != nil {
+		return "invalid"
+	}
+
+	if move.IsDrop() {
+		return string(pieceToFENPiece(getWhitePiece(move.Piece))) + "@" + end
+	}
+
+	promotion := ""
+	if move.IsPromotion() {
+		promotion = string(pieceToFENPiece(getPromotedPiece(move.Flag)))
+	}
+	return start + end + promotion
+}
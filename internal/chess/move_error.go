@@ -0,0 +1,28 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrIllegalMove   = errors.New("illegal move")
+	ErrAmbiguousMove = errors.New("ambiguous move")
+	ErrMalformedMove = errors.New("malformed move")
+	ErrWrongSide     = errors.New("it is not this side's turn to move")
+)
+
+// MoveError describes why a move given as a Move, in algebraic notation or in UCI notation could not be made.
+// Err is one of ErrIllegalMove, ErrAmbiguousMove, ErrMalformedMove, ErrWrongSide or ErrGameOver.
+type MoveError struct {
+	Move string
+	Err  error
+}
+
+func (err *MoveError) Error() string {
+	return fmt.Sprintf("%q: %s", err.Move, err.Err)
+}
+
+func (err *MoveError) Unwrap() error {
+	return err.Err
+}
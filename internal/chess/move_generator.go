@@ -27,429 +27,532 @@ var knightOffsets [8]Position = [8]Position{
 	{X: 1, Y: -2},
 }
 
+// MoveStage selects which subset of the legal moves is generated.
+type MoveStage uint8
+
+const (
+	// AllMoves are every legal move.
+	AllMoves MoveStage = iota
+	// CaptureMoves are captures, including en passant, and promotions.
+	CaptureMoves
+	// QuietMoves are the moves which are neither captures nor promotions, including castling.
+	QuietMoves
+	// CheckingMoves are the moves which put the enemy king in check.
+	CheckingMoves
+)
+
+// moveGenerator holds the information about a state needed to generate only legal moves for the active color.
+type moveGenerator struct {
+	state        *State
+	stage        MoveStage
+	own, enemy   Bitboard
+	occupied     Bitboard
+	kingPosition Position
+	kingSquare   Square
+	// checkers holds the enemy pieces giving check.
+	checkers Bitboard
+	// checkMask holds the squares a piece other than the king can move to in order to resolve a check.
+	checkMask Bitboard
+	// pinned holds the pieces which cannot leave the line between their king and an enemy slider.
+	pinned Bitboard
+	// enemyKingSquare and discoverers are only set for CheckingMoves.
+	// discoverers holds the pieces which would give a discovered check by moving off the line to the enemy king.
+	enemyKingSquare Square
+	discoverers     Bitboard
+	// atomic is set when kings cannot capture and legality is instead checked by making each move, as in Atomic.
+	atomic bool
+	// antichess is set when the king moves and captures as an ordinary piece, there are no checks or pins and pawns may promote to a king, as in Antichess.
+	antichess bool
+}
+
 // GenerateAllMoves generates all possible moves in a given state.
 func (state *State) GenerateAllMoves() (moves []Move, err error) {
-	kingPosition, err := state.Board.findKing(state.ActiveColor)
-	if err != nil {
-		return moves, err
-	}
+	return state.generateMoveSlice(AllMoves)
+}
 
-	for i := int8(0); i < 8; i++ {
-		for j := int8(0); j < 8; j++ {
-			position := Position{i, j}
-			square := state.Board.GetSquare(position)
+// GenerateCaptures generates the legal captures, including en passant, and promotions in a given state.
+func (state *State) GenerateCaptures() (moves []Move, err error) {
+	return state.generateMoveSlice(CaptureMoves)
+}
 
-			// Skip any squares that aren't of the active color
-			if (state.ActiveColor == Black && square <= 0) || (state.ActiveColor == White && square >= 0) {
-				continue
-			}
+// GenerateQuietMoves generates the legal moves which are neither captures nor promotions in a given state, including castling.
+// Together with GenerateCaptures this gives every legal move exactly once.
+func (state *State) GenerateQuietMoves() (moves []Move, err error) {
+	return state.generateMoveSlice(QuietMoves)
+}
 
-			switch square {
-			case WhitePawn, BlackPawn:
-				moves = append(moves, state.GeneratePawnMoves(position, kingPosition)...)
-			case WhiteBishop, BlackBishop:
-				moves = append(moves, state.GenerateBishopMoves(position, kingPosition)...)
-			case WhiteKnight, BlackKnight:
-				moves = append(moves, state.GenerateKnightMoves(position, kingPosition)...)
-			case WhiteRook, BlackRook:
-				moves = append(moves, state.GenerateRookMoves(position, kingPosition)...)
-			case WhiteQueen, BlackQueen:
-				moves = append(moves, state.GenerateQueenMoves(position, kingPosition)...)
-			case WhiteKing, BlackKing:
-				moves = append(moves, state.GenerateKingMoves(position)...)
-			default:
-				// Pass on empty squares
-			}
-		}
-	}
+// GenerateChecks generates the legal moves which put the enemy king in check in a given state.
+func (state *State) GenerateChecks() (moves []Move, err error) {
+	return state.generateMoveSlice(CheckingMoves)
+}
 
-	return
+// GenerateEvasions generates the legal moves out of check in a given state.
+// No moves are generated if the active color is not in check.
+func (state *State) GenerateEvasions() (moves []Move, err error) {
+	var list MoveList
+	err = state.GenerateEvasionsInto(&list)
+	return list.ToSlice(), err
 }
 
-func (state *State) GenerateKingMoves(position Position) (moves []Move) {
-	piece := state.Board[position.X][position.Y]
+// GenerateAllMovesInto replaces the contents of the list with all possible moves in a given state, without allocating.
+func (state *State) GenerateAllMovesInto(list *MoveList) error {
+	return state.generateMoves(list, AllMoves)
+}
 
-	directions := make([]Position, 8)
-	directions = append(directions, bishopDirections[:]...)
-	directions = append(directions, rookDirections[:]...)
+// GenerateCapturesInto replaces the contents of the list with the moves from GenerateCaptures, without allocating.
+func (state *State) GenerateCapturesInto(list *MoveList) error {
+	return state.generateMoves(list, CaptureMoves)
+}
 
-	// Normal directional moves
-	for _, offset := range directions {
-		pos := AddPositions(position, offset)
-		if isInBounds(pos) &&
-			isValidSquare(piece, state.Board[pos.X][pos.Y]) {
+// GenerateQuietMovesInto replaces the contents of the list with the moves from GenerateQuietMoves, without allocating.
+func (state *State) GenerateQuietMovesInto(list *MoveList) error {
+	return state.generateMoves(list, QuietMoves)
+}
 
-			possibleMove := Move{
-				position,
-				pos,
-				None,
-				state.Board[pos.X][pos.Y],
-			}
+// GenerateChecksInto replaces the contents of the list with the moves from GenerateChecks, without allocating.
+func (state *State) GenerateChecksInto(list *MoveList) error {
+	return state.generateMoves(list, CheckingMoves)
+}
 
-			// Check that this move does not lead to the king being put into check
-			state.Board.DoMove(possibleMove)
-			if !state.Board.IsSquareAttacked(pos, state.ActiveColor) {
-				moves = append(moves, possibleMove)
-			}
-			state.Board.UndoMove(possibleMove)
-		}
+// GenerateEvasionsInto replaces the contents of the list with the moves from GenerateEvasions, without allocating.
+func (state *State) GenerateEvasionsInto(list *MoveList) error {
+	list.Clear()
+
+	isInCheck, err := state.IsInCheck(state.ActiveColor)
+	if err != nil || !isInCheck {
+		return err
 	}
 
-	// Castling
-	if state.ActiveColor == Black && !state.Board.IsSquareAttacked(position, Black) {
-
-		kingSidePositionFinish := Position{0, 6}
-		kingSidePositionSkip := Position{0, 5}
-
-		// Check if king side castling is legal (empty squares, no castling through check, etc.)
-		if state.CastlingRights.BlackCanCastleKingSide &&
-			state.Board.GetSquare(kingSidePositionSkip) == EmptySquare &&
-			state.Board.GetSquare(kingSidePositionFinish) == EmptySquare &&
-			!state.Board.IsSquareAttacked(kingSidePositionSkip, Black) &&
-			!state.Board.IsSquareAttacked(kingSidePositionFinish, Black) {
-
-			moves = append(moves, Move{
-				position,
-				kingSidePositionFinish,
-				KingSideCastle,
-				EmptySquare,
-			})
-		}
+	return state.generateMoves(list, AllMoves)
+}
 
-		queenSidePositionRookSkip := Position{0, 1}
-		queenSidePositionFinish := Position{0, 2}
-		queenSidePositionSkip := Position{0, 3}
-
-		// Check if queen side castling is legal (empty squares, no castling through check, etc.)
-		if state.CastlingRights.BlackCanCastleQueenSide &&
-			state.Board.GetSquare(queenSidePositionSkip) == EmptySquare &&
-			state.Board.GetSquare(queenSidePositionFinish) == EmptySquare &&
-			state.Board.GetSquare(queenSidePositionRookSkip) == EmptySquare &&
-			!state.Board.IsSquareAttacked(queenSidePositionSkip, Black) &&
-			!state.Board.IsSquareAttacked(queenSidePositionFinish, Black) {
-
-			moves = append(moves, Move{
-				position,
-				queenSidePositionFinish,
-				QueenSideCastle,
-				EmptySquare,
-			})
-		}
-	} else if state.ActiveColor == White && !state.Board.IsSquareAttacked(position, White) {
-
-		kingSidePositionFinish := Position{7, 6}
-		kingSidePositionSkip := Position{7, 5}
-
-		// Check if king side castling is legal (empty squares, no castling through check, etc.)
-		if state.CastlingRights.WhiteCanCastleKingSide &&
-			state.Board.GetSquare(kingSidePositionSkip) == EmptySquare &&
-			state.Board.GetSquare(kingSidePositionFinish) == EmptySquare &&
-			!state.Board.IsSquareAttacked(kingSidePositionSkip, White) &&
-			!state.Board.IsSquareAttacked(kingSidePositionFinish, White) {
-
-			moves = append(moves, Move{
-				position,
-				kingSidePositionFinish,
-				KingSideCastle,
-				EmptySquare,
-			})
-		}
+// generateMoveSlice generates the legal moves of the given stage into a new slice.
+func (state *State) generateMoveSlice(stage MoveStage) ([]Move, error) {
+	var list MoveList
+	err := state.generateMoves(&list, stage)
+	return list.ToSlice(), err
+}
+
+// generateStandardMoves replaces the contents of the list with the legal moves of the given stage, ordered by the square of the moving piece,
+// followed by the drops of the pieces in the active color's pocket if drops are allowed.
+func (state *State) generateStandardMoves(moves *MoveList, stage MoveStage, drops bool) error {
+	moves.Clear()
 
-		queenSidePositionRookSkip := Position{7, 1}
-		queenSidePositionFinish := Position{7, 2}
-		queenSidePositionSkip := Position{7, 3}
-
-		// Check if queen side castling is legal (empty squares, no castling through check, etc.)
-		if state.CastlingRights.WhiteCanCastleQueenSide &&
-			state.Board.GetSquare(queenSidePositionSkip) == EmptySquare &&
-			state.Board.GetSquare(queenSidePositionFinish) == EmptySquare &&
-			state.Board.GetSquare(queenSidePositionRookSkip) == EmptySquare &&
-			!state.Board.IsSquareAttacked(queenSidePositionSkip, White) &&
-			!state.Board.IsSquareAttacked(queenSidePositionFinish, White) {
-
-			moves = append(moves, Move{
-				position,
-				queenSidePositionFinish,
-				QueenSideCastle,
-				EmptySquare,
-			})
+	kingPosition, err := state.Board.FindKing(state.ActiveColor)
+	if err != nil {
+		return err
+	}
+
+	generator := state.newMoveGenerator(kingPosition)
+	if stage == CheckingMoves {
+		enemyKingPosition, err := state.Board.FindKing(!state.ActiveColor)
+		if err != nil {
+			return err
 		}
+		generator.setCheckingMoveTargets(squareOf(enemyKingPosition))
+	}
+	generator.stage = stage
+	generator.appendMoves(moves)
+
+	// Drops are never captures
+	if stage != CaptureMoves && drops {
+		generator.appendDrops(moves)
+	}
+
+	// The target squares only narrow down the checking moves, so each one is confirmed by executing it
+	if stage == CheckingMoves {
+		moves.filter(state.givesCheck)
 	}
 
-	return
+	return nil
 }
 
-func (state *State) GenerateQueenMoves(position, kingPosition Position) (moves []Move) {
-	moves = append(moves, state.generateDirectionalMoves(position, kingPosition, rookDirections)...)
-	moves = append(moves, state.generateDirectionalMoves(position, kingPosition, bishopDirections)...)
+// appendMoves appends the moves of every piece of the active color.
+func (generator *moveGenerator) appendMoves(moves *MoveList) {
+	for pieces := generator.own; pieces != 0; {
+		square := pieces.popSquare()
+
+		switch generator.state.Board.squares[square] {
+		case WhitePawn, BlackPawn:
+			generator.appendPawnMoves(moves, square)
+		case WhiteBishop, BlackBishop:
+			generator.appendPieceMoves(moves, square, bishopAttacks(square, generator.occupied))
+		case WhiteKnight, BlackKnight:
+			generator.appendPieceMoves(moves, square, knightAttacks[square])
+		case WhiteRook, BlackRook:
+			generator.appendPieceMoves(moves, square, rookAttacks(square, generator.occupied))
+		case WhiteQueen, BlackQueen:
+			generator.appendPieceMoves(moves, square, bishopAttacks(square, generator.occupied)|rookAttacks(square, generator.occupied))
+		case WhiteKing, BlackKing:
+			if generator.antichess {
+				generator.appendPieceMoves(moves, square, kingAttacks[square])
+			} else {
+				generator.appendKingMoves(moves)
+			}
+		}
+	}
+}
+
+func (state *State) GenerateKingMoves(position Position) []Move {
+	var moves MoveList
+	generator := state.newMoveGenerator(position)
+	generator.appendKingMoves(&moves)
+	return moves.ToSlice()
+}
 
-	return
+func (state *State) GenerateQueenMoves(position, kingPosition Position) []Move {
+	var moves MoveList
+	square := squareOf(position)
+	generator := state.newMoveGenerator(kingPosition)
+	generator.appendPieceMoves(&moves, square, rookAttacks(square, generator.occupied)|bishopAttacks(square, generator.occupied))
+	return moves.ToSlice()
 }
 
 func (state *State) GenerateRookMoves(position, kingPosition Position) []Move {
-	return state.generateDirectionalMoves(position, kingPosition, rookDirections)
+	var moves MoveList
+	square := squareOf(position)
+	generator := state.newMoveGenerator(kingPosition)
+	generator.appendPieceMoves(&moves, square, rookAttacks(square, generator.occupied))
+	return moves.ToSlice()
 }
 
 func (state *State) GenerateBishopMoves(position, kingPosition Position) []Move {
-	return state.generateDirectionalMoves(position, kingPosition, bishopDirections)
+	var moves MoveList
+	square := squareOf(position)
+	generator := state.newMoveGenerator(kingPosition)
+	generator.appendPieceMoves(&moves, square, bishopAttacks(square, generator.occupied))
+	return moves.ToSlice()
 }
 
-func (state *State) GenerateKnightMoves(position, kingPosition Position) (moves []Move) {
-	checkIllegalMove := state.getIllegalMoveChecker(kingPosition)
-
-	for _, knightPosition := range getKnightVision(position) {
-		if isValidSquare(state.Board.GetSquare(position), state.Board.GetSquare(knightPosition)) {
-			move := Move{
-				position,
-				knightPosition,
-				None,
-				state.Board.GetSquare((knightPosition)),
-			}
-			if !checkIllegalMove(move) {
-				moves = append(moves, move)
-			}
-		}
-	}
-
-	return
+func (state *State) GenerateKnightMoves(position, kingPosition Position) []Move {
+	var moves MoveList
+	square := squareOf(position)
+	generator := state.newMoveGenerator(kingPosition)
+	generator.appendPieceMoves(&moves, square, knightAttacks[square])
+	return moves.ToSlice()
 }
 
-func (state *State) GeneratePawnMoves(position, kingPosition Position) (moves []Move) {
+func (state *State) GeneratePawnMoves(position, kingPosition Position) []Move {
+	var moves MoveList
+	generator := state.newMoveGenerator(kingPosition)
+	generator.appendPawnMoves(&moves, squareOf(position))
+	return moves.ToSlice()
+}
 
-	isEnemyPiece := func(piecePosition Position) bool {
-		piece := state.Board.GetSquare(piecePosition)
-		return (state.ActiveColor == Black && piece < 0) ||
-			(state.ActiveColor == White && piece > 0)
+// newMoveGenerator finds the checks and pins on the active color's king.
+func (state *State) newMoveGenerator(kingPosition Position) moveGenerator {
+	board := &state.Board
+	generator := moveGenerator{
+		state:        state,
+		own:          board.ColorPieces(state.ActiveColor),
+		enemy:        board.ColorPieces(!state.ActiveColor),
+		occupied:     board.Occupied(),
+		kingPosition: kingPosition,
+		kingSquare:   squareOf(kingPosition),
 	}
 
-	checkIllegalMove := state.getIllegalMoveChecker(kingPosition)
-
-	isPromotion := false
-	var pawnDirection int8
-	var doublePushAvailable bool
-	if state.ActiveColor == Black {
-		pawnDirection = 1
-
-		// Check if pawn is on the last rank
-		if position.X == 6 {
-			isPromotion = true
-		}
-		doublePushAvailable = position.X == 1
-	} else {
-		pawnDirection = -1
+	generator.checkers = board.attackersTo(generator.kingSquare, generator.occupied) & generator.enemy
+	switch generator.checkers.Count() {
+	case 0:
+		generator.checkMask = allSquares
+	case 1:
+		checker := generator.checkers.firstSquare()
+		generator.checkMask = generator.checkers | betweenSquares[generator.kingSquare][checker]
+	default:
+		// Only the king can move out of a double check
+		generator.checkMask = 0
+	}
 
-		// Check if pawn is on the last rank
-		if position.X == 1 {
-			isPromotion = true
+	// Any enemy slider which would attack the king on an empty board may be pinning a piece
+	for snipers := board.snipers(generator.kingSquare, !state.ActiveColor); snipers != 0; {
+		sniper := snipers.popSquare()
+		blockers := betweenSquares[generator.kingSquare][sniper] & generator.occupied
+		if blockers.Count() == 1 && blockers&generator.own != 0 {
+			generator.pinned |= blockers
 		}
-		doublePushAvailable = position.X == 6
 	}
 
-	// Normal moves
-	pushPosition := AddPositions(position, Position{pawnDirection, 0})
-	if isInBounds(pushPosition) && state.Board.GetSquare(pushPosition) == EmptySquare {
-		move := Move{
-			position,
-			pushPosition,
-			None,
-			EmptySquare,
-		}
+	return generator
+}
 
-		if !checkIllegalMove(move) {
-			if isPromotion {
-				moves = append(moves, getMovesForPromotion(move)...)
-			} else {
-				moves = append(moves, move)
-			}
+// setCheckingMoveTargets finds the pieces which could give a discovered check to the enemy king on the given square.
+func (generator *moveGenerator) setCheckingMoveTargets(enemyKingSquare Square) {
+	board := &generator.state.Board
+	color := generator.state.ActiveColor
+	generator.enemyKingSquare = enemyKingSquare
+
+	for snipers := board.snipers(enemyKingSquare, color); snipers != 0; {
+		sniper := snipers.popSquare()
+		blockers := betweenSquares[enemyKingSquare][sniper] & generator.occupied
+		if blockers.Count() == 1 && blockers&generator.own != 0 {
+			generator.discoverers |= blockers
 		}
+	}
+}
 
-		// Double push
-		if doublePushAvailable {
-			doublePushPosition := AddPositions(position, Position{pawnDirection * 2, 0})
-			if isInBounds(doublePushPosition) && state.Board.GetSquare(doublePushPosition) == EmptySquare {
-				move = Move{
-					position,
-					doublePushPosition,
-					None,
-					EmptySquare,
-				}
+// getTargetMask returns the squares a piece of the active color other than the king may legally move to.
+func (generator *moveGenerator) getTargetMask(from Square) Bitboard {
+	mask := generator.checkMask &^ generator.own
+	if generator.pinned&squareBitboard(from) != 0 {
+		mask &= lineSquares[generator.kingSquare][from]
+	}
+	return mask & generator.getStageMask(from)
+}
 
-				if !checkIllegalMove(move) {
-					moves = append(moves, move)
-				}
-			}
+// getStageMask returns the squares a piece other than a pawn or king may move to in order to be part of the current stage.
+// Moves to these squares for CheckingMoves may not give check, but every checking move is included.
+func (generator *moveGenerator) getStageMask(from Square) Bitboard {
+	switch generator.stage {
+	case CaptureMoves:
+		return generator.enemy
+	case QuietMoves:
+		return ^generator.occupied
+	case CheckingMoves:
+		if generator.discoverers&squareBitboard(from) != 0 {
+			return allSquares
 		}
+		return generator.getCheckSquares(generator.state.Board.squares[from])
 	}
+	return allSquares
+}
 
-	capturePositions := [2]Position{
-		AddPositions(position, Position{pawnDirection, 1}),
-		AddPositions(position, Position{pawnDirection, -1}),
+// getCheckSquares returns the squares from which the given piece of the active color would attack the enemy king.
+func (generator *moveGenerator) getCheckSquares(piece Piece) Bitboard {
+	enemyKing := generator.enemyKingSquare
+	switch piece {
+	case WhiteKnight, BlackKnight:
+		return knightAttacks[enemyKing]
+	case WhiteBishop, BlackBishop:
+		return bishopAttacks(enemyKing, generator.occupied)
+	case WhiteRook, BlackRook:
+		return rookAttacks(enemyKing, generator.occupied)
+	case WhiteQueen, BlackQueen:
+		return bishopAttacks(enemyKing, generator.occupied) | rookAttacks(enemyKing, generator.occupied)
+	case WhitePawn, BlackPawn:
+		return pawnAttacks[colorIndex(!generator.state.ActiveColor)][enemyKing]
 	}
+	return 0
+}
 
-	for _, capturePosition := range capturePositions {
-		if isInBounds(capturePosition) && isEnemyPiece(capturePosition) {
-			move := Move{
-				position,
-				capturePosition,
-				None,
-				state.Board.GetSquare(capturePosition),
-			}
+// appendPieceMoves appends the legal moves of a knight, bishop, rook or queen given the squares it attacks.
+func (generator *moveGenerator) appendPieceMoves(moves *MoveList, from Square, attacks Bitboard) {
+	for targets := attacks & generator.getTargetMask(from); targets != 0; {
+		to := targets.popSquare()
+		moves.Add(encodeMove(from, to, None, generator.state.Board.squares[to], generator.state.Board.squares[from]))
+	}
+}
 
-			if !checkIllegalMove(move) {
-				if isPromotion {
-					moves = append(moves, getMovesForPromotion(move)...)
-				} else {
-					moves = append(moves, move)
-				}
-			}
-		} else if state.EnPassantPosition.Ok && capturePosition == state.EnPassantPosition.Position {
-			move := Move{
-				position,
-				capturePosition,
-				EnPassant,
-				getEnemyPawnColor(state.ActiveColor),
-			}
+// appendKingMoves appends the legal moves of the active color's king, including castling.
+func (generator *moveGenerator) appendKingMoves(moves *MoveList) {
+	board := &generator.state.Board
 
-			if !checkIllegalMove(move) {
-				moves = append(moves, move)
-			}
+	// The king is removed so that it cannot block an attack along the line it is moving on
+	occupied := generator.occupied &^ squareBitboard(generator.kingSquare)
+	targets := kingAttacks[generator.kingSquare] &^ generator.own
+	if generator.atomic {
+		// A king cannot capture in Atomic, as it would be caught in the explosion
+		targets &^= generator.enemy
+	}
+	switch generator.stage {
+	case CaptureMoves:
+		targets &= generator.enemy
+	case QuietMoves:
+		targets &^= generator.enemy
+	case CheckingMoves:
+		// The king can only give check by uncovering an attack or by castling
+		if generator.discoverers&squareBitboard(generator.kingSquare) == 0 {
+			targets = 0
 		}
 	}
 
-	return
-}
-
-// Checks if a square is attacked by the opposite color to defenderSide
-func (board *Board) IsSquareAttacked(position Position, defenderSide Color) bool {
+	for targets != 0 {
+		to := targets.popSquare()
+		if !generator.atomic && board.attackersTo(to, occupied)&generator.enemy != 0 {
+			continue
+		}
 
-	// Check for attacks by pawn
-	var pawnDirection int8
-	var pawnType Piece
-	if defenderSide == Black {
-		pawnType = WhitePawn
-		pawnDirection = 1
-	} else if defenderSide == White {
-		pawnType = BlackPawn
-		pawnDirection = -1
+		moves.Add(encodeMove(generator.kingSquare, to, None, board.squares[to], board.squares[generator.kingSquare]))
 	}
 
-	pawnPositions := [2]Position{
-		AddPositions(position, Position{pawnDirection, 1}),
-		AddPositions(position, Position{pawnDirection, -1}),
+	// Castling
+	if generator.checkers == 0 && generator.stage != CaptureMoves {
+		for _, kingSide := range [2]bool{true, false} {
+			if move, ok := generator.state.getCastlingMove(generator.kingPosition, kingSide); ok {
+				moves.Add(move.ToEncoded())
+			}
+		}
 	}
+}
 
-	for _, pawnPosition := range pawnPositions {
-		if isInBounds(pawnPosition) &&
-			board.GetSquare(pawnPosition) == pawnType {
-
-			return true
-		}
+// appendPawnMoves appends the legal moves of a pawn, including promotions and en passant.
+func (generator *moveGenerator) appendPawnMoves(moves *MoveList, from Square) {
+	state := generator.state
+	start := from.Position()
+	targetMask := generator.checkMask &^ generator.own
+	if generator.pinned&squareBitboard(from) != 0 {
+		targetMask &= lineSquares[generator.kingSquare][from]
 	}
 
-	// Check for attacks by knight
-	knightMoves := getKnightVision(position)
-	for _, knightVision := range knightMoves {
-		if (defenderSide == Black && board.GetSquare(knightVision) == WhiteKnight) ||
-			(defenderSide == White && board.GetSquare(knightVision) == BlackKnight) {
+	forward, startRank := Square(-8), int8(6)
+	promotionSquares := rank8
+	if state.ActiveColor == Black {
+		forward, startRank = 8, 1
+		promotionSquares = rank1
+	}
 
-			return true
+	// Promotions belong with the captures, and are confirmed as checks by executing them
+	switch generator.stage {
+	case CaptureMoves:
+		targetMask &= generator.enemy | promotionSquares
+	case QuietMoves:
+		targetMask &^= generator.enemy | promotionSquares
+	case CheckingMoves:
+		if generator.discoverers&squareBitboard(from) == 0 {
+			targetMask &= generator.getStageMask(from) | promotionSquares
 		}
 	}
 
-	// Check for bishop type attacks
-	bishopVisions := board.getDirectionalVision(position, bishopDirections)
-	for _, bishopVision := range bishopVisions {
-		// Don't consider the square itself
-		if !bishopVision.Ok {
-			continue
-		}
-		seenPiece := board.GetSquare(bishopVision.Position)
-		if (defenderSide == Black && (seenPiece == WhiteBishop || seenPiece == WhiteQueen)) ||
-			(defenderSide == White && (seenPiece == BlackBishop || seenPiece == BlackQueen)) {
+	appendPawnMove := func(to Square) {
+		move := encodeMove(from, to, None, state.Board.squares[to], state.Board.squares[from])
 
-			return true
+		if promotionSquares&squareBitboard(to) != 0 {
+			appendPromotions(moves, move)
+			if generator.antichess {
+				moves.Add(move.withFlag(PromoteToKing))
+			}
+		} else {
+			moves.Add(move)
 		}
 	}
 
-	// Check for rook type attacks
-	rookVisions := board.getDirectionalVision(position, rookDirections)
-	for _, rookVision := range rookVisions {
-		// Don't consider the square itself
-		if !rookVision.Ok {
-			continue
+	// Normal moves
+	push := from + forward
+	if generator.occupied&squareBitboard(push) == 0 {
+		if targetMask&squareBitboard(push) != 0 {
+			appendPawnMove(push)
 		}
-		seenPiece := board.GetSquare(rookVision.Position)
-		if (defenderSide == Black && (seenPiece == WhiteRook || seenPiece == WhiteQueen)) ||
-			(defenderSide == White && (seenPiece == BlackRook || seenPiece == BlackQueen)) {
 
-			return true
+		// Double push
+		doublePush := push + forward
+		if start.X == startRank && generator.occupied&squareBitboard(doublePush) == 0 && targetMask&squareBitboard(doublePush) != 0 {
+			appendPawnMove(doublePush)
 		}
 	}
 
-	// Check for attacks by king
-	for _, offset := range rookDirections {
-		singleRookMove := AddPositions(position, offset)
-		if !isInBounds(singleRookMove) {
-			continue
-		}
-		square := board.GetSquare(singleRookMove)
-		if (defenderSide == Black && square == WhiteKing) ||
-			(defenderSide == White && square == BlackKing) {
+	// Captures
+	attacks := pawnAttacks[colorIndex(state.ActiveColor)][from]
+	for targets := attacks & generator.enemy & targetMask; targets != 0; {
+		appendPawnMove(targets.popSquare())
+	}
 
-			return true
+	// En passant removes two pieces from the same rank, so it is checked by executing the move unless there is no king to protect
+	if state.EnPassantPosition.Ok && attacks.Contains(state.EnPassantPosition.Position) && generator.stage != QuietMoves {
+		move := Move{
+			start,
+			state.EnPassantPosition.Position,
+			EnPassant,
+			getEnemyPawnColor(state.ActiveColor),
+			colorPiece(WhitePawn, state.ActiveColor),
 		}
-	}
-	for _, offset := range bishopDirections {
-		singleBishopMove := AddPositions(position, offset)
-		if !isInBounds(singleBishopMove) {
-			continue
+		if generator.antichess {
+			moves.Add(move.ToEncoded())
+			return
 		}
-		square := board.GetSquare(singleBishopMove)
-		if (defenderSide == Black && square == WhiteKing) ||
-			(defenderSide == White && square == BlackKing) {
 
-			return true
+		state.Board.DoMove(move)
+		isAttacked := state.Board.IsSquareAttacked(generator.kingPosition, state.ActiveColor)
+		state.Board.UndoMove(move)
+
+		if !isAttacked || generator.atomic {
+			moves.Add(move.ToEncoded())
 		}
 	}
+}
+
+// givesCheck returns whether the move puts the enemy king in check.
+func (state *State) givesCheck(move Move) bool {
+	state.Board.DoMove(move)
+	isInCheck, err := state.Board.IsInCheck(!state.ActiveColor)
+	state.Board.UndoMove(move)
 
-	return false
+	return err == nil && isInCheck
 }
 
-// generateDirectionalMoves gives the available legal moves given a set of direction.
-func (state *State) generateDirectionalMoves(position, kingPosition Position, directions [4]Position) (moves []Move) {
-	checkIllegalMove := state.getIllegalMoveChecker(kingPosition)
+// Checks if a square is attacked by the opposite color to defenderSide
+func (board *Board) IsSquareAttacked(position Position, defenderSide Color) bool {
+	return board.attackersTo(squareOf(position), board.Occupied())&board.ColorPieces(!defenderSide) != 0
+}
 
-	for i := 0; i < 4; i++ {
-		offset := int8(1)
-		for {
-			nextPosition := AddPositions(position, MultiplyScalar(directions[i], offset))
-			if !isInBounds(nextPosition) {
-				break
-			}
+// attackersTo returns the pieces of either color which attack the square, given the occupied squares.
+func (board *Board) attackersTo(square Square, occupied Bitboard) Bitboard {
+	bishops := board.Pieces(WhiteBishop) | board.Pieces(BlackBishop) | board.Pieces(WhiteQueen) | board.Pieces(BlackQueen)
+	rooks := board.Pieces(WhiteRook) | board.Pieces(BlackRook) | board.Pieces(WhiteQueen) | board.Pieces(BlackQueen)
+
+	return (pawnAttacks[colorIndex(White)][square] & board.Pieces(BlackPawn)) |
+		(pawnAttacks[colorIndex(Black)][square] & board.Pieces(WhitePawn)) |
+		(knightAttacks[square] & (board.Pieces(WhiteKnight) | board.Pieces(BlackKnight))) |
+		(kingAttacks[square] & (board.Pieces(WhiteKing) | board.Pieces(BlackKing))) |
+		(bishopAttacks(square, occupied) & bishops) |
+		(rookAttacks(square, occupied) & rooks)
+}
 
-			possibleMove := Move{
-				position,
-				nextPosition,
-				None,
-				state.Board.GetSquare(nextPosition),
-			}
+// getCastlingMove returns the castling move on the given side if it is legal.
+// The king is assumed not to be in check.
+func (state *State) getCastlingMove(kingPosition Position, kingSide bool) (Move, bool) {
+	if !state.CastlingRights.CanCastle(state.ActiveColor, kingSide) {
+		return Move{}, false
+	}
 
-			// If the square is empty or an unfriendly piece and executing the move does not result in a check, add it to the list
-			nextSquare := state.Board.GetSquare(nextPosition)
-			isFriendlyPiece := (state.ActiveColor == Black && nextSquare > 0) || (state.ActiveColor == White && nextSquare < 0)
-			if !isFriendlyPiece && !checkIllegalMove(possibleMove) {
-				moves = append(moves, possibleMove)
-			}
+	rookPosition := state.CastlingRookPosition(state.ActiveColor, kingSide)
+	king := state.Board.GetSquare(kingPosition)
+	if kingPosition != state.CastlingKingPosition(state.ActiveColor) || state.Board.GetSquare(rookPosition) != getRookColorForKing(king) {
+		return Move{}, false
+	}
 
-			// Break on any non-empty squares
-			if nextSquare != EmptySquare {
-				break
+	rank := kingPosition.X
+	flag, kingFinish, rookFinish := KingSideCastle, int8(6), int8(5)
+	if !kingSide {
+		flag, kingFinish, rookFinish = QueenSideCastle, 2, 3
+	}
+
+	// Every square the king and rook travel across must be empty, other than the squares they start on
+	isPathClear := func(start, finish int8) bool {
+		for file := min(start, finish); file <= max(start, finish); file++ {
+			if file != kingPosition.Y && file != rookPosition.Y && state.Board.GetSquare(Position{rank, file}) != EmptySquare {
+				return false
 			}
-			offset++
 		}
+		return true
+	}
+	if !isPathClear(kingPosition.Y, kingFinish) || !isPathClear(rookPosition.Y, rookFinish) {
+		return Move{}, false
+	}
+
+	// The king may not castle through check
+	step := int8(1)
+	if kingFinish < kingPosition.Y {
+		step = -1
+	}
+	for file := kingPosition.Y; file != kingFinish; file += step {
+		if state.Board.IsSquareAttacked(Position{rank, file + step}, state.ActiveColor) {
+			return Move{}, false
+		}
+	}
+
+	move := Move{
+		kingPosition,
+		rookPosition,
+		flag,
+		EmptySquare,
+		king,
 	}
 
-	return
+	// In Chess960 the castling rook can be shielding the king's final square
+	state.Board.DoMove(move)
+	isAttacked := state.Board.IsSquareAttacked(Position{rank, kingFinish}, state.ActiveColor)
+	state.Board.UndoMove(move)
+
+	return move, !isAttacked
 }
 
 // getDirectionalVision gives the extents of the vision/movement of a piece.
@@ -491,31 +594,19 @@ func getKnightVision(position Position) []Position {
 	return validPositions
 }
 
-// findKing returns the position of the king with the specified color.
-func (board *Board) findKing(color Color) (kingPosition Position, err error) {
-	var square Piece
-
-	var i int8
-	var j int8
-	for i = 0; i < 8; i++ {
-		for j = 0; j < 8; j++ {
-			kingPosition = Position{i, j}
-			square = board.GetSquare(kingPosition)
-
-			if (color == Black && square == BlackKing) ||
-				(color == White && square == WhiteKing) {
-
-				return kingPosition, nil
-			}
-		}
+// FindKing returns the position of the king with the specified color.
+func (board *Board) FindKing(color Color) (kingPosition Position, err error) {
+	kings := board.Pieces(colorPiece(WhiteKing, color))
+	if kings == 0 {
+		return Position{}, errors.New("missing king")
 	}
 
-	return Position{}, errors.New("missing king")
+	return kings.firstSquare().Position(), nil
 }
 
-// isInCheck returns whether the given color's king is in check.
-func (board *Board) isInCheck(color Color) (bool, error) {
-	kingPosition, err := board.findKing(color)
+// IsInCheck returns whether the given color's king is in check.
+func (board *Board) IsInCheck(color Color) (bool, error) {
+	kingPosition, err := board.FindKing(color)
 	if err != nil {
 		return false, err
 	}
@@ -528,49 +619,9 @@ func isInBounds(position Position) bool {
 	return position.X >= 0 && position.X <= 7 && position.Y >= 0 && position.Y <= 7
 }
 
-// isValidSquare checks if the square is empty or an enemy piece.
-func isValidSquare(piece1, piece2 Piece) bool {
-	return piece1*piece2 <= 0
-}
-
-// getIllegalMoveChecker returns a method that checks whether a given move is legal with the current state and king square.
-func (state *State) getIllegalMoveChecker(kingPosition Position) func(move Move) bool {
-	return func(move Move) bool {
-		state.Board.DoMove(move)
-		isAttacked := state.Board.IsSquareAttacked(kingPosition, state.ActiveColor)
-		state.Board.UndoMove(move)
-		return isAttacked
-	}
-}
-
-// getMovesForPromotion takes the positional information from the parameter and returns an array with each type of promotion.
-func getMovesForPromotion(move Move) []Move {
-	moves := make([]Move, 4)
-
-	moves[0] = Move{
-		move.Start,
-		move.End,
-		PromoteToQueen,
-		move.Captured,
+// appendPromotions takes the positional information from the move and appends a move for each type of promotion.
+func appendPromotions(moves *MoveList, move EncodedMove) {
+	for _, flag := range [4]MoveFlag{PromoteToQueen, PromoteToRook, PromoteToBishop, PromoteToKnight} {
+		moves.Add(move.withFlag(flag))
 	}
-	moves[1] = Move{
-		move.Start,
-		move.End,
-		PromoteToRook,
-		move.Captured,
-	}
-	moves[2] = Move{
-		move.Start,
-		move.End,
-		PromoteToBishop,
-		move.Captured,
-	}
-	moves[3] = Move{
-		move.Start,
-		move.End,
-		PromoteToKnight,
-		move.Captured,
-	}
-
-	return moves
 }
@@ -44,14 +44,11 @@ func TestMoveGenerationAndExecution(t *testing.T) {
 				t.Errorf("move not generated %s", result.Move)
 			}
 
-			castlingRights := test.Initial.CastlingRights
-			enPassantSquare := test.Initial.EnPassantPosition
-
-			test.Initial.DoMove(move)
+			undo := test.Initial.DoMove(move)
 			if test.Initial != result.Result {
 				t.Errorf("incorrect resultant state for move %s\nexpected=\n%s\nactual=\n%s", result.Move, BoardToDisplayString(result.Result.Board), BoardToDisplayString(test.Initial.Board))
 			}
-			test.Initial.UndoMove(move, castlingRights, enPassantSquare)
+			test.Initial.UndoMove(undo)
 		}
 	}
 }
@@ -80,7 +77,6 @@ func TestMoveCountStartingPositionDepth6(t *testing.T) {
 	testMoveCount(t, InitialiseState(), 119060324, 6)
 }
 
-// TODO: Add further positions
 // Reference:
 // https://www.chessprogramming.org/Perft_Results
 
@@ -104,6 +100,88 @@ func TestMoveCountPosition5Depth5(t *testing.T) {
 	testMoveCount(t, getPosition5(), 89941194, 5)
 }
 
+func TestMoveCountKiwipeteDepth1(t *testing.T) {
+	testMoveCount(t, getKiwipete(), 48, 1)
+}
+
+func TestMoveCountKiwipeteDepth2(t *testing.T) {
+	testMoveCount(t, getKiwipete(), 2039, 2)
+}
+
+func TestMoveCountKiwipeteDepth3(t *testing.T) {
+	testMoveCount(t, getKiwipete(), 97862, 3)
+}
+
+func TestMoveCountKiwipeteDepth4(t *testing.T) {
+	testMoveCount(t, getKiwipete(), 4085603, 4)
+}
+
+func TestMoveCountPosition3Depth5(t *testing.T) {
+	testMoveCount(t, getPosition3(), 674624, 5)
+}
+
+func BenchmarkMoveCountStartingPositionDepth4(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		getMoveCount(InitialiseState(), 4)
+	}
+}
+
+func BenchmarkMoveCountKiwipeteDepth3(b *testing.B) {
+	state := getKiwipete()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		getMoveCount(state, 3)
+	}
+}
+
+func BenchmarkGenerateAllMovesInto(b *testing.B) {
+	state := getKiwipete()
+	var moves MoveList
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		state.GenerateAllMovesInto(&moves)
+	}
+}
+
+func BenchmarkGenerateCapturesInto(b *testing.B) {
+	state := getKiwipete()
+	var moves MoveList
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		state.GenerateCapturesInto(&moves)
+	}
+}
+
+func TestMoveGenerationDoesNotAllocate(t *testing.T) {
+	states := []State{InitialiseState(), getPosition5(), getKiwipete(), getPosition3()}
+	var moves MoveList
+
+	allocations := testing.AllocsPerRun(100, func() {
+		for i := range states {
+			states[i].GenerateAllMovesInto(&moves)
+			states[i].GenerateCapturesInto(&moves)
+			states[i].GenerateQuietMovesInto(&moves)
+			states[i].GenerateChecksInto(&moves)
+			states[i].GenerateEvasionsInto(&moves)
+		}
+	})
+	if allocations != 0 {
+		t.Errorf("move generation allocated %v times", allocations)
+	}
+
+	kiwipete := getKiwipete()
+	allocations = testing.AllocsPerRun(10, func() {
+		getMoveCount(kiwipete, 3)
+	})
+	if allocations != 0 {
+		t.Errorf("move count allocated %v times", allocations)
+	}
+}
+
 func testMoveCount(t *testing.T, state State, expected, depth int) {
 	moveCount, err := getMoveCount(state, depth)
 	if err != nil {
@@ -121,20 +199,16 @@ func getMoveCount(state State, depth int) (int, error) {
 	}
 	depth--
 
-	possibleMoves, err := state.GenerateAllMoves()
-	if err != nil {
+	var possibleMoves MoveList
+	if err := state.GenerateAllMovesInto(&possibleMoves); err != nil {
 		return 0, err
 	}
 
 	var total int = 0
-	for _, newMove := range possibleMoves {
-		// Save previous state information which is not contained within a move
-		castlingRights := state.CastlingRights
-		enPassantSquare := state.EnPassantPosition
-
-		state.DoMove(newMove)
+	for _, newMove := range possibleMoves.Moves() {
+		undo := state.DoMove(newMove.ToMove())
 		moveCount, err := getMoveCount(state, depth)
-		state.UndoMove(newMove, castlingRights, enPassantSquare)
+		state.UndoMove(undo)
 
 		if err != nil {
 			return 0, err
@@ -146,24 +220,113 @@ func getMoveCount(state State, depth int) (int, error) {
 }
 
 func getPosition5() State {
-	return State{
-		Board{
-			{BlackRook, BlackKnight, BlackBishop, BlackQueen, EmptySquare, BlackKing, EmptySquare, BlackRook},
-			{BlackPawn, BlackPawn, EmptySquare, WhitePawn, BlackBishop, BlackPawn, BlackPawn, BlackPawn},
-			{EmptySquare, EmptySquare, BlackPawn, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare},
-			{EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare},
-			{EmptySquare, EmptySquare, WhiteBishop, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare},
-			{EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare, EmptySquare},
-			{WhitePawn, WhitePawn, WhitePawn, EmptySquare, WhiteKnight, BlackKnight, WhitePawn, WhitePawn},
-			{WhiteRook, WhiteKnight, WhiteBishop, WhiteQueen, WhiteKing, EmptySquare, EmptySquare, WhiteRook},
-		},
-		CastlingRights{
-			true,
-			true,
-			false,
-			false,
-		},
-		White,
-		PositionOpt{Ok: false},
+	state, err := ParseFEN("rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8")
+	if err != nil {
+		panic(err)
+	}
+
+	return state
+}
+
+func getKiwipete() State {
+	state, err := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		panic(err)
+	}
+
+	return state
+}
+
+func getPosition3() State {
+	state, err := ParseFEN("8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1")
+	if err != nil {
+		panic(err)
+	}
+
+	return state
+}
+
+func TestStagedMoveGeneration(t *testing.T) {
+	chess960State, err := InitialiseChess960State(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, state := range []State{InitialiseState(), getPosition5(), getKiwipete(), getPosition3(), chess960State} {
+		testStagedMoveGeneration(t, state, 3)
+	}
+}
+
+func testStagedMoveGeneration(t *testing.T, state State, depth int) {
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	captures, err := state.GenerateCaptures()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	quietMoves, err := state.GenerateQuietMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	checks, err := state.GenerateChecks()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	evasions, err := state.GenerateEvasions()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectedCaptures, expectedQuietMoves, expectedChecks := map[Move]bool{}, map[Move]bool{}, map[Move]bool{}
+	for _, move := range moves {
+		if move.IsCapture() || move.IsPromotion() {
+			expectedCaptures[move] = true
+		} else {
+			expectedQuietMoves[move] = true
+		}
+		if state.givesCheck(move) {
+			expectedChecks[move] = true
+		}
+	}
+
+	isInCheck, err := state.Board.IsInCheck(state.ActiveColor)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expectedEvasions := map[Move]bool{}
+	if isInCheck {
+		for _, move := range moves {
+			expectedEvasions[move] = true
+		}
+	}
+
+	compareMoveSets(t, state, "captures", expectedCaptures, captures)
+	compareMoveSets(t, state, "quiet moves", expectedQuietMoves, quietMoves)
+	compareMoveSets(t, state, "checks", expectedChecks, checks)
+	compareMoveSets(t, state, "evasions", expectedEvasions, evasions)
+
+	if depth <= 1 {
+		return
+	}
+	for _, move := range moves {
+		next := state
+		next.DoMove(move)
+		testStagedMoveGeneration(t, next, depth-1)
+	}
+}
+
+func compareMoveSets(t *testing.T, state State, stage string, expected map[Move]bool, actual []Move) {
+	found := make(map[Move]bool, len(actual))
+	for _, move := range actual {
+		if !expected[move] || found[move] {
+			t.Fatalf("unexpected %s move %v in %s", stage, move, state.FEN())
+		}
+		found[move] = true
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("expected %d %s but found %d in %s", len(expected), stage, len(found), state.FEN())
 	}
 }
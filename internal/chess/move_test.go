@@ -10,7 +10,28 @@ type testMoveData struct {
 func TestEncodeMove(t *testing.T) {
 	for _, test := range retrieveTestData() {
 		if test.encoded != test.move.ToEncoded() {
-			t.Fatalf("Move: %v does not match encoded move: %b", test.move, test.encoded)
+			t.Fatalf("Move: %+v does not match encoded move: %x", test.move, test.encoded)
+		}
+	}
+}
+
+func TestEncodeGeneratedMoves(t *testing.T) {
+	state, err := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, move := range moves {
+		if move.Piece != state.Board.GetSquare(move.Start) {
+			t.Errorf("generated move %+v has the wrong moving piece", move)
+		}
+		if decoded := move.ToEncoded().ToMove(); decoded != move {
+			t.Errorf("move %+v was decoded as %+v", move, decoded)
 		}
 	}
 }
@@ -18,85 +39,153 @@ func TestEncodeMove(t *testing.T) {
 func TestDecodeMove(t *testing.T) {
 	for _, test := range retrieveTestData() {
 		if test.move != test.encoded.ToMove() {
-			t.Fatalf("Encoded move: %b does not match move: %v", test.encoded, test.move)
+			t.Fatalf("Encoded move: %x does not match move: %+v", test.encoded, test.move)
 		}
 	}
 }
 
-// TODO: fix for changes to board
+// retrieveTestData returns moves with every flag, including the Drop and PromoteToKing flags of variants, for both colors and with and without captures.
 func retrieveTestData() []testMoveData {
 	return []testMoveData{
 		{
 			move: Move{
-				Start: Position{1, 1},
-				End:   Position{2, 2},
-				Flag:  None,
+				Start:    Position{6, 4},
+				End:      Position{4, 4},
+				Flag:     None,
+				Captured: EmptySquare,
+				Piece:    WhitePawn,
+			},
+			encoded: 0x650934,
+		},
+		{
+			move: Move{
+				Start:    Position{7, 6},
+				End:      Position{5, 5},
+				Flag:     None,
+				Captured: EmptySquare,
+				Piece:    WhiteKnight,
+			},
+			encoded: 0x640b7e,
+		},
+		{
+			move: Move{
+				Start:    Position{3, 3},
+				End:      Position{1, 5},
+				Flag:     None,
+				Captured: BlackBishop,
+				Piece:    WhiteQueen,
+			},
+			encoded: 0x91035b,
+		},
+		{
+			move: Move{
+				Start:    Position{3, 4},
+				End:      Position{2, 3},
+				Flag:     EnPassant,
+				Captured: BlackPawn,
+				Piece:    WhitePawn,
+			},
+			encoded: 0x7514dc,
+		},
+		{
+			move: Move{
+				Start:    Position{4, 2},
+				End:      Position{5, 1},
+				Flag:     EnPassant,
+				Captured: WhitePawn,
+				Piece:    BlackPawn,
+			},
+			encoded: 0x571a62,
+		},
+		{
+			move: Move{
+				Start:    Position{7, 4},
+				End:      Position{7, 7},
+				Flag:     KingSideCastle,
+				Captured: EmptySquare,
+				Piece:    WhiteKing,
 			},
-			encoded: 0b0000010010001001,
+			encoded: 0x602ffc,
 		},
 		{
 			move: Move{
-				Start: Position{1, 3},
-				End:   Position{1, 5},
-				Flag:  None,
+				Start:    Position{0, 1},
+				End:      Position{0, 0},
+				Flag:     QueenSideCastle,
+				Captured: EmptySquare,
+				Piece:    BlackKing,
 			},
-			encoded: 0b0000101001011001,
+			encoded: 0x6c3001,
 		},
 		{
 			move: Move{
-				Start: Position{4, 7},
-				End:   Position{6, 7},
-				Flag:  KingSideCastle,
+				Start:    Position{1, 6},
+				End:      Position{0, 6},
+				Flag:     PromoteToQueen,
+				Captured: EmptySquare,
+				Piece:    WhitePawn,
 			},
-			encoded: 0b0010111110111100,
+			encoded: 0x65418e,
 		},
 		{
 			move: Move{
-				Start: Position{4, 0},
-				End:   Position{2, 0},
-				Flag:  QueenSideCastle,
+				Start:    Position{1, 6},
+				End:      Position{0, 7},
+				Flag:     PromoteToRook,
+				Captured: BlackRook,
+				Piece:    WhitePawn,
 			},
-			encoded: 0b0011000010000100,
+			encoded: 0xa551ce,
 		},
 		{
 			move: Move{
-				Start: Position{4, 3},
-				End:   Position{5, 2},
-				Flag:  EnPassant,
+				Start:    Position{6, 0},
+				End:      Position{7, 0},
+				Flag:     PromoteToBishop,
+				Captured: EmptySquare,
+				Piece:    BlackPawn,
 			},
-			encoded: 0b0001010101011100,
+			encoded: 0x676e30,
 		},
 		{
 			move: Move{
-				Start: Position{0, 6},
-				End:   Position{0, 7},
-				Flag:  PromoteToQueen,
+				Start:    Position{6, 0},
+				End:      Position{7, 1},
+				Flag:     PromoteToKnight,
+				Captured: WhiteQueen,
+				Piece:    BlackPawn,
 			},
-			encoded: 0b0100111000110000,
+			encoded: 0x177e70,
 		},
 		{
 			move: Move{
-				Start: Position{0, 6},
-				End:   Position{0, 7},
-				Flag:  PromoteToKnight,
+				Start:    Position{5, 5},
+				End:      Position{5, 5},
+				Flag:     Drop,
+				Captured: EmptySquare,
+				Piece:    WhiteKnight,
 			},
-			encoded: 0b0111111000110000,
+			encoded: 0x648b6d,
 		},
 		{
 			move: Move{
-				Start: Position{0, 6},
-				End:   Position{0, 7},
-				Flag:  PromoteToBishop,
+				Start:    Position{6, 2},
+				End:      Position{7, 2},
+				Flag:     PromoteToKing,
+				Captured: EmptySquare,
+				Piece:    BlackPawn,
 			},
-			encoded: 0b0110111000110000,
+			encoded: 0x679eb2,
 		},
 		{
 			move: Move{
-				Start: Position{0, 6},
-				End:   Position{0, 7},
-				Flag:  PromoteToRook,
+				Start:    Position{1, 1},
+				End:      Position{0, 0},
+				Flag:     PromoteToKing,
+				Captured: BlackRook,
+				Piece:    WhitePawn,
 			},
-			encoded: 0b0101111000110000,
+			encoded: 0xa59009,
 		},
 	}
 }
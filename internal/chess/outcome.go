@@ -0,0 +1,114 @@
+package chess
+
+import "fmt"
+
+// Result represents the score of a game.
+type Result uint8
+
+const (
+	Ongoing Result = iota
+	WhiteWins
+	BlackWins
+	Draw
+)
+
+// Termination represents the reason a game ended.
+type Termination uint8
+
+const (
+	Unterminated Termination = iota
+	Checkmate
+	Stalemate
+	ThreefoldRepetition
+	FivefoldRepetition
+	FiftyMoveRule
+	SeventyFiveMoveRule
+	InsufficientMaterial
+	Resignation
+	Timeout
+	Agreement
+	Abandonment
+	ThreeChecks
+	KingOfTheHillReached
+	KingExploded
+	AllPiecesLost
+)
+
+// Outcome represents the result of a game along with the reason it ended.
+type Outcome struct {
+	Result      Result
+	Termination Termination
+}
+
+// String returns the result as written in PGN: 1-0, 0-1, 1/2-1/2 or * for an ongoing game.
+func (result Result) String() string {
+	switch result {
+	case WhiteWins:
+		return "1-0"
+	case BlackWins:
+		return "0-1"
+	case Draw:
+		return "1/2-1/2"
+	default:
+		return "*"
+	}
+}
+
+// String returns a human readable description of the termination reason.
+func (termination Termination) String() string {
+	switch termination {
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case FiftyMoveRule:
+		return "fifty-move rule"
+	case SeventyFiveMoveRule:
+		return "seventy-five-move rule"
+	case InsufficientMaterial:
+		return "insufficient material"
+	case Resignation:
+		return "resignation"
+	case Timeout:
+		return "timeout"
+	case Agreement:
+		return "agreement"
+	case Abandonment:
+		return "abandonment"
+	case ThreeChecks:
+		return "three checks"
+	case KingOfTheHillReached:
+		return "king of the hill"
+	case KingExploded:
+		return "king exploded"
+	case AllPiecesLost:
+		return "all pieces lost"
+	default:
+		return "unterminated"
+	}
+}
+
+// IsOver returns whether the outcome represents a finished game.
+func (outcome Outcome) IsOver() bool {
+	return outcome.Result != Ongoing
+}
+
+// String returns a human readable description of the outcome, e.g. "1-0 (checkmate)".
+func (outcome Outcome) String() string {
+	if !outcome.IsOver() {
+		return outcome.Result.String()
+	}
+	return fmt.Sprintf("%s (%s)", outcome.Result, outcome.Termination)
+}
+
+// winFor returns the outcome where the given color wins with the given termination.
+func winFor(color Color, termination Termination) Outcome {
+	if color == Black {
+		return Outcome{BlackWins, termination}
+	}
+	return Outcome{WhiteWins, termination}
+}
@@ -90,6 +90,14 @@ func getKnightColorForPawn(pawn Piece) Piece {
 	}
 }
 
+// getWhitePiece returns the white piece of the same type as the given piece.
+func getWhitePiece(piece Piece) Piece {
+	if piece > 0 {
+		return -piece
+	}
+	return piece
+}
+
 func getEnemyPawnColor(color Color) Piece {
 	if color == Black {
 		return WhitePawn
@@ -107,3 +115,20 @@ func getSameColorPawn(piece Piece) Piece {
 		return EmptySquare
 	}
 }
+
+// getPromotedPiece returns the black piece of the type a promotion flag promotes to, or an empty square for other flags.
+func getPromotedPiece(flag MoveFlag) Piece {
+	switch flag {
+	case PromoteToQueen:
+		return BlackQueen
+	case PromoteToRook:
+		return BlackRook
+	case PromoteToBishop:
+		return BlackBishop
+	case PromoteToKnight:
+		return BlackKnight
+	case PromoteToKing:
+		return BlackKing
+	}
+	return EmptySquare
+}
@@ -0,0 +1,101 @@
+package chess
+
+// seeValues gives the value of each piece type in centipawns, indexed by the black piece.
+// The king is given a value larger than all other material so that it is never exchanged.
+var seeValues = [7]int{
+	EmptySquare: 0,
+	BlackPawn:   100,
+	BlackKnight: 300,
+	BlackBishop: 300,
+	BlackRook:   500,
+	BlackQueen:  900,
+	BlackKing:   20000,
+}
+
+// pieceValue returns the value of a piece of either color in centipawns.
+func pieceValue(piece Piece) int {
+	if piece < 0 {
+		piece = -piece
+	}
+	return seeValues[piece]
+}
+
+// SEE returns the static exchange evaluation of a move in centipawns, which is the material the moving side gains after the best sequence of captures on the move's destination.
+// Each side captures with its least valuable piece and may stop capturing at any point.
+// Attackers hidden behind sliders are included once the pieces in front of them have captured, but pins are ignored.
+// Castling moves do not risk any material and are given a value of 0.
+func (state *State) SEE(move Move) int {
+	if move.IsCastle() {
+		return 0
+	}
+
+	board := &state.Board
+	from, to := squareOf(move.Start), squareOf(move.End)
+	occupied := board.Occupied() &^ squareBitboard(from)
+
+	// gains holds the material won by the side to move at each capture, assuming the exchange stops after it
+	var gains [32]int
+	gains[0] = pieceValue(board.squares[to])
+	onSquare := pieceValue(board.squares[from])
+
+	switch {
+	case move.Flag == EnPassant:
+		gains[0] = pieceValue(BlackPawn)
+		occupied &^= PositionBitboard(Position{move.Start.X, move.End.Y})
+	case move.IsPromotion():
+		promoted := pieceValue(getPromotedPiece(move.Flag))
+		gains[0] += promoted - pieceValue(BlackPawn)
+		onSquare = promoted
+	}
+
+	color := !state.ActiveColor
+	promotionRank := to.Position().X == 0 || to.Position().X == 7
+	depth := 0
+	for depth < len(gains)-1 {
+		attackers := board.attackersTo(to, occupied) & occupied
+		ownAttackers := attackers & board.ColorPieces(color)
+		if ownAttackers == 0 {
+			break
+		}
+
+		attacker, piece := board.leastValuablePiece(ownAttackers)
+
+		// The king can only capture if the square is no longer defended
+		if piece == WhiteKing || piece == BlackKing {
+			if attackers&board.ColorPieces(!color) != 0 {
+				break
+			}
+		}
+
+		depth++
+		gains[depth] = onSquare - gains[depth-1]
+		onSquare = pieceValue(piece)
+		if (piece == WhitePawn || piece == BlackPawn) && promotionRank {
+			gains[depth] += pieceValue(BlackQueen) - pieceValue(BlackPawn)
+			onSquare = pieceValue(BlackQueen)
+		}
+
+		occupied &^= squareBitboard(attacker)
+		color = !color
+	}
+
+	// Each side chooses between stopping and continuing the exchange, working back from the last capture
+	for ; depth > 0; depth-- {
+		gains[depth-1] = -max(-gains[depth-1], gains[depth])
+	}
+
+	return gains[0]
+}
+
+// leastValuablePiece returns the square and piece of the least valuable piece among the given squares, which must not be empty.
+func (board *Board) leastValuablePiece(squares Bitboard) (Square, Piece) {
+	for _, piece := range [6]Piece{BlackPawn, BlackKnight, BlackBishop, BlackRook, BlackQueen, BlackKing} {
+		if pieces := squares & (board.Pieces(piece) | board.Pieces(-piece)); pieces != 0 {
+			square := pieces.firstSquare()
+			return square, board.squares[square]
+		}
+	}
+
+	square := squares.firstSquare()
+	return square, board.squares[square]
+}
@@ -0,0 +1,53 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		fen      string
+		move     AlgebraicNotation
+		expected int
+	}{
+		// Undefended pawn
+		{"1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "Rxe5", 100},
+		// Batteries behind the rook and bishop on both sides
+		{"1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "Nxe5", -200},
+		// The rook behind the capturing rook recaptures
+		{"3rk3/8/8/3p4/8/8/3R4/3R2K1 w - - 0 1", "Rxd5", 100},
+		{"3rk3/8/8/3p4/8/8/3R4/6K1 w - - 0 1", "Rxd5", -400},
+		// The king cannot recapture on a defended square
+		{"3R4/8/8/8/8/3pk3/8/1B4K1 w - - 0 1", "Rxd3", 100},
+		{"3R4/8/8/8/8/3pk3/8/6K1 w - - 0 1", "Rxd3", -400},
+		// Promotions
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8=Q", 800},
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8=Q", -100},
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "bxa8=Q", 1300},
+		{"4k3/8/8/8/8/1N6/1p6/r3K3 w - - 0 1", "Nxa1", -600},
+		// En passant
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", 100},
+		{"4k3/2p5/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", 0},
+		// Quiet moves
+		{"4k3/8/8/2p5/8/8/8/3QK3 w - - 0 1", "Qd4", -900},
+		{"4k3/8/8/2p5/8/8/8/3QK3 w - - 0 1", "Qd3", 0},
+		{"r3k3/8/8/8/8/8/8/4K2R w K - 0 1", "O-O", 0},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		move, err := test.move.ToMove(state)
+		if err != nil {
+			t.Errorf("%s in %s: %s", test.move, test.fen, err.Error())
+			continue
+		}
+
+		if see := state.SEE(move); see != test.expected {
+			t.Errorf("incorrect SEE for %s in %s: expected=%d; actual=%d", test.move, test.fen, test.expected, see)
+		}
+	}
+}
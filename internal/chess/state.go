@@ -10,8 +10,36 @@ const (
 type State struct {
 	Board             Board
 	CastlingRights    CastlingRights
+	CastlingRookFiles CastlingRookFiles
+	CastlingKingFiles CastlingKingFiles
 	ActiveColor       Color
 	EnPassantPosition PositionOpt
+	HalfMoveClock     int
+	FullMoveNumber    int
+	// Variant is the set of rules the position is played under, with nil treated as Standard.
+	Variant Variant
+	// Checks is the number of checks given by each side, which is only tracked by variants such as Three-check.
+	Checks CheckCounts
+	// Pockets holds the pieces each side may drop, and Promoted the squares of pieces which were promoted from pawns, which are only used by variants such as Crazyhouse.
+	Pockets  Pockets
+	Promoted Bitboard
+	// Hash is the Zobrist hash of the position, which is kept up to date by DoMove and UndoMove.
+	Hash uint64
+}
+
+// UndoRecord holds everything needed to revert a move made by State.DoMove.
+// Move is the move which was made, with Piece and Captured set from the board.
+type UndoRecord struct {
+	Move              Move
+	CastlingRights    CastlingRights
+	EnPassantPosition PositionOpt
+	HalfMoveClock     int
+	FullMoveNumber    int
+	Checks            CheckCounts
+	Pockets           Pockets
+	Promoted          Bitboard
+	Explosion         Explosion
+	Hash              uint64
 }
 
 type CastlingRights struct {
@@ -21,8 +49,26 @@ type CastlingRights struct {
 	BlackCanCastleQueenSide bool
 }
 
+// CastlingRookFiles holds the file each castling rook starts on, which only differs from the a and h files in Chess960.
+type CastlingRookFiles struct {
+	WhiteKingSide  int8
+	WhiteQueenSide int8
+	BlackKingSide  int8
+	BlackQueenSide int8
+}
+
+var standardCastlingRookFiles = CastlingRookFiles{7, 0, 7, 0}
+
+// CastlingKingFiles holds the file each king castles from, which is only different from the e file in Chess960.
+type CastlingKingFiles struct {
+	White int8
+	Black int8
+}
+
+var standardCastlingKingFiles = CastlingKingFiles{4, 4}
+
 func InitialiseState() State {
-	return State{
+	state := State{
 		InitialiseBoard(),
 		CastlingRights{
 			true,
@@ -30,46 +76,85 @@ func InitialiseState() State {
 			true,
 			true,
 		},
+		standardCastlingRookFiles,
+		standardCastlingKingFiles,
 		White,
 		PositionOpt{Ok: false},
+		0,
+		1,
+		Standard,
+		CheckCounts{},
+		Pockets{},
+		0,
+		0,
 	}
+	state.Hash = state.ComputeHash()
+	return state
 }
 
-// DoMove takes in a state and a move and executes the move, returning the updated state.
-func (state *State) DoMove(move Move) {
+// DoMove takes in a state and a move and executes the move, returning a record which UndoMove can use to revert it.
+// The moving and captured pieces are read from the board, so Move.Piece and Move.Captured do not need to be set except for the piece of a drop.
+func (state *State) DoMove(move Move) UndoRecord {
+	record := UndoRecord{
+		move,
+		state.CastlingRights,
+		state.EnPassantPosition,
+		state.HalfMoveClock,
+		state.FullMoveNumber,
+		state.Checks,
+		state.Pockets,
+		state.Promoted,
+		Explosion{},
+		state.Hash,
+	}
+
+	if !move.IsDrop() {
+		record.Move.Piece = state.Board.GetSquare(move.Start)
+	}
+	switch {
+	case move.Flag == EnPassant:
+		record.Move.Captured = getEnemyPawnColor(state.ActiveColor)
+	case move.IsCastle(), move.IsDrop():
+		record.Move.Captured = EmptySquare
+	default:
+		record.Move.Captured = state.Board.GetSquare(move.End)
+	}
+	move = record.Move
+
 	castlingRights := state.CastlingRights
 	whiteCanCastleKingSide := castlingRights.WhiteCanCastleKingSide
 	whiteCanCastleQueenSide := castlingRights.WhiteCanCastleQueenSide
 	blackCanCastleKingSide := castlingRights.BlackCanCastleKingSide
 	blackCanCastleQueenSide := castlingRights.BlackCanCastleQueenSide
 
-	if state.ActiveColor == Black && (move.Start == Position{0, 4}) {
+	movedPiece := move.Piece
+	if movedPiece == BlackKing {
 		blackCanCastleKingSide = false
 		blackCanCastleQueenSide = false
-	} else if state.ActiveColor == White && (move.Start == Position{7, 4}) {
+	} else if movedPiece == WhiteKing {
 		whiteCanCastleKingSide = false
 		whiteCanCastleQueenSide = false
 	}
 
 	// Check for rooks moving or being captured
-	if MoveTouchesSquare(move, Position{0, 0}) {
+	if MoveTouchesSquare(move, state.CastlingRookPosition(Black, false)) {
 		blackCanCastleQueenSide = false
 	}
-	if MoveTouchesSquare(move, Position{0, 7}) {
+	if MoveTouchesSquare(move, state.CastlingRookPosition(Black, true)) {
 		blackCanCastleKingSide = false
 	}
-	if MoveTouchesSquare(move, Position{7, 0}) {
+	if MoveTouchesSquare(move, state.CastlingRookPosition(White, false)) {
 		whiteCanCastleQueenSide = false
 	}
-	if MoveTouchesSquare(move, Position{7, 7}) {
+	if MoveTouchesSquare(move, state.CastlingRookPosition(White, true)) {
 		whiteCanCastleKingSide = false
 	}
 
 	enPassantSquare := PositionOpt{
 		Ok: false,
 	}
-	if (state.ActiveColor == Black && state.Board.GetSquare(move.Start) == BlackPawn && (move.End.X-move.Start.X == 2)) ||
-		(state.ActiveColor == White && state.Board.GetSquare(move.Start) == WhitePawn && (move.Start.X-move.End.X == 2)) {
+	if (state.ActiveColor == Black && movedPiece == BlackPawn && (move.End.X-move.Start.X == 2)) ||
+		(state.ActiveColor == White && movedPiece == WhitePawn && (move.Start.X-move.End.X == 2)) {
 
 		enPassantSquare.Ok = true
 		enPassantSquare.Position = Position{
@@ -78,20 +163,130 @@ func (state *State) DoMove(move Move) {
 		}
 	}
 
-	state.Board.DoMove(move)
+	// The halfmove clock is reset by any capture or pawn move
+	halfMoveClock := state.HalfMoveClock + 1
+	if movedPiece == WhitePawn || movedPiece == BlackPawn || move.IsCapture() {
+		halfMoveClock = 0
+	}
+
+	fullMoveNumber := state.FullMoveNumber
+	if state.ActiveColor == Black {
+		fullMoveNumber++
+	}
+
+	// Remove the keys for the squares the move changes and the old state, then add them back after the move
+	changedSquares := state.changedSquares(move)
+	state.Hash ^= state.Board.zobristSquaresKey(changedSquares) ^ state.zobristStateKey()
+
 	state.CastlingRights = CastlingRights{
 		whiteCanCastleKingSide,
 		whiteCanCastleQueenSide,
 		blackCanCastleKingSide,
 		blackCanCastleQueenSide,
 	}
-	state.ActiveColor = !state.ActiveColor
 	state.EnPassantPosition = enPassantSquare
+	state.HalfMoveClock = halfMoveClock
+	state.FullMoveNumber = fullMoveNumber
+	record = state.makeMove(record)
+	state.ActiveColor = !state.ActiveColor
+
+	state.Hash ^= state.Board.zobristSquaresKey(changedSquares) ^ state.zobristStateKey()
+
+	return record
 }
 
-func (state *State) UndoMove(move Move, castlingRights CastlingRights, enPassantSquare PositionOpt) {
-	state.Board.UndoMove(move)
-	state.CastlingRights = castlingRights
+// UndoMove reverts the move made by the call to DoMove which returned the record.
+// Moves must be undone in the reverse order to which they were made.
+func (state *State) UndoMove(record UndoRecord) {
 	state.ActiveColor = !state.ActiveColor
-	state.EnPassantPosition = enPassantSquare
+	state.unmakeMove(record)
+	state.CastlingRights = record.CastlingRights
+	state.EnPassantPosition = record.EnPassantPosition
+	state.HalfMoveClock = record.HalfMoveClock
+	state.FullMoveNumber = record.FullMoveNumber
+	state.Checks = record.Checks
+	state.Pockets = record.Pockets
+	state.Promoted = record.Promoted
+	state.Hash = record.Hash
+}
+
+// CastlingRookPosition returns the starting square of the rook used to castle on the given side.
+func (state *State) CastlingRookPosition(color Color, kingSide bool) Position {
+	switch {
+	case color == White && kingSide:
+		return Position{7, state.CastlingRookFiles.WhiteKingSide}
+	case color == White:
+		return Position{7, state.CastlingRookFiles.WhiteQueenSide}
+	case kingSide:
+		return Position{0, state.CastlingRookFiles.BlackKingSide}
+	default:
+		return Position{0, state.CastlingRookFiles.BlackQueenSide}
+	}
+}
+
+// CastlingKingPosition returns the square the given color's king must be on to castle.
+func (state *State) CastlingKingPosition(color Color) Position {
+	if color == White {
+		return Position{7, state.CastlingKingFiles.White}
+	}
+	return Position{0, state.CastlingKingFiles.Black}
+}
+
+// CanCastle returns whether the given color still has the right to castle on the given side.
+func (castlingRights CastlingRights) CanCastle(color Color, kingSide bool) bool {
+	switch {
+	case color == White && kingSide:
+		return castlingRights.WhiteCanCastleKingSide
+	case color == White:
+		return castlingRights.WhiteCanCastleQueenSide
+	case kingSide:
+		return castlingRights.BlackCanCastleKingSide
+	default:
+		return castlingRights.BlackCanCastleQueenSide
+	}
+}
+
+// setCastlingRight sets the right of the given color to castle on the given side.
+func (castlingRights *CastlingRights) setCastlingRight(color Color, kingSide bool, allowed bool) {
+	switch {
+	case color == White && kingSide:
+		castlingRights.WhiteCanCastleKingSide = allowed
+	case color == White:
+		castlingRights.WhiteCanCastleQueenSide = allowed
+	case kingSide:
+		castlingRights.BlackCanCastleKingSide = allowed
+	default:
+		castlingRights.BlackCanCastleQueenSide = allowed
+	}
+}
+
+// setCastlingRookFile sets the starting file of the rook the given color castles with on the given side.
+func (castlingRookFiles *CastlingRookFiles) setCastlingRookFile(color Color, kingSide bool, file int8) {
+	switch {
+	case color == White && kingSide:
+		castlingRookFiles.WhiteKingSide = file
+	case color == White:
+		castlingRookFiles.WhiteQueenSide = file
+	case kingSide:
+		castlingRookFiles.BlackKingSide = file
+	default:
+		castlingRookFiles.BlackQueenSide = file
+	}
+}
+
+// setCastlingKingFile sets the file the given color's king castles from.
+func (castlingKingFiles *CastlingKingFiles) setCastlingKingFile(color Color, file int8) {
+	if color == White {
+		castlingKingFiles.White = file
+	} else {
+		castlingKingFiles.Black = file
+	}
+}
+
+// backRank returns the index of the rank the given color's pieces start on.
+func backRank(color Color) int8 {
+	if color == Black {
+		return 0
+	}
+	return 7
 }
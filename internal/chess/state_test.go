@@ -0,0 +1,55 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestUndoMoveWithoutCapturedPiece(t *testing.T) {
+	for _, fen := range []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+	} {
+		state, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		moves, err := state.GenerateAllMoves()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		initial := state
+		for _, move := range moves {
+			// Moves keep their moving and captured pieces through their compressed form, so the record matches the generated move
+			undo := state.DoMove(move.ToEncoded().ToMove())
+			if undo.Move != move {
+				t.Errorf("incorrect move in undo record: expected=%+v; actual=%+v", move, undo.Move)
+			}
+
+			state.UndoMove(undo)
+			if state != initial {
+				t.Fatalf("incorrect state after undoing %s: expected=%s; actual=%s", move, fen, state.FEN())
+			}
+		}
+	}
+}
+
+func TestUndoRecordRestoresClocks(t *testing.T) {
+	state, err := ParseFEN("4k3/8/8/8/8/8/8/4K2R b K - 12 40")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	initial := state
+
+	undo := state.DoMove(Move{Start: Position{0, 4}, End: Position{1, 4}})
+	if state.HalfMoveClock != 13 || state.FullMoveNumber != 41 {
+		t.Errorf("incorrect clocks after move: %s", state.FEN())
+	}
+
+	state.UndoMove(undo)
+	if state != initial {
+		t.Errorf("incorrect state after undo: expected=%s; actual=%s", initial.FEN(), state.FEN())
+	}
+}
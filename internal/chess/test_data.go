@@ -80,7 +80,7 @@ func jsonToFormattedTestData(filePath string) ([]moveGenerationTestData, error)
 	var testData = make([]moveGenerationTestData, 0, len(fenTestCases.TestCases))
 
 	for _, testCase := range fenTestCases.TestCases {
-		initialState, err := fenToState(testCase.Start.Fen)
+		initialState, err := ParseFEN(testCase.Start.Fen)
 		if err != nil {
 			return []moveGenerationTestData{}, err
 		}
@@ -88,7 +88,7 @@ func jsonToFormattedTestData(filePath string) ([]moveGenerationTestData, error)
 		var expected = make([]moveGenerationResultTestData, 0, len(testCase.Expected))
 
 		for _, expectedFenState := range testCase.Expected {
-			expectedState, err := fenToState(expectedFenState.Fen)
+			expectedState, err := ParseFEN(expectedFenState.Fen)
 			if err != nil {
 				return []moveGenerationTestData{}, err
 			}
@@ -108,3 +108,39 @@ func jsonToFormattedTestData(filePath string) ([]moveGenerationTestData, error)
 
 	return testData, nil
 }
+
+// loadTestFENs returns every FEN string found in the test data.
+func loadTestFENs() ([]string, error) {
+	fens := []string{}
+	err := filepath.WalkDir("../../test_data", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip non json files
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		byteValue, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var fenTestCases fenTestCases
+		err = json.Unmarshal(byteValue, &fenTestCases)
+		if err != nil {
+			return err
+		}
+
+		for _, testCase := range fenTestCases.TestCases {
+			fens = append(fens, testCase.Start.Fen)
+			for _, expected := range testCase.Expected {
+				fens = append(fens, expected.Fen)
+			}
+		}
+		return nil
+	})
+
+	return fens, err
+}
@@ -0,0 +1,107 @@
+package chess
+
+import "strings"
+
+// UCINotation represents a move in the coordinate notation used by UCI, such as e2e4, e7e8q or e1g1.
+type UCINotation string
+
+// ToUCINotation converts from a move struct to UCI notation.
+// Castling is given by the king's destination, such as e1g1, unless kingTakesRook is set,
+// in which case it is given by the king capturing its own rook, such as e1h1, as in the Chess960 mode of UCI.
+func (move Move) ToUCINotation(kingTakesRook bool) UCINotation {
+	if move.IsCastle() && !kingTakesRook {
+		move.End = castlingKingDestination(move)
+	}
+	return UCINotation(move.String())
+}
+
+// ToMove converts from UCI notation to a move struct.
+// Castling is accepted either by the king's destination or by the king capturing its own rook.
+// An error wrapping ErrMalformedMove, ErrIllegalMove or ErrWrongSide is returned if the notation does not describe a legal move.
+func (notation UCINotation) ToMove(state State) (Move, error) {
+	legalMoves, err := state.GenerateAllMoves()
+	if err != nil {
+		return Move{}, err
+	}
+
+	return state.parseUCIMove(string(notation), legalMoves)
+}
+
+// parseUCIMove returns the legal move given in the coordinate notation used by UCI, such as e2e4 or e7e8q.
+// Castling may be given either by the king's destination or by the king capturing its own rook, and drops by the upper case piece and the square, such as N@f3.
+func (state *State) parseUCIMove(notation string, legalMoves []Move) (Move, error) {
+	if len(notation) == 4 && notation[1] == '@' {
+		return parseUCIDrop(notation, legalMoves)
+	}
+
+	if len(notation) != 4 && len(notation) != 5 {
+		return Move{}, &MoveError{notation, ErrMalformedMove}
+	}
+
+	start, err := stringToPosition(notation[0:2])
+	if err != nil {
+		return Move{}, &MoveError{notation, ErrMalformedMove}
+	}
+	end, err := stringToPosition(notation[2:4])
+	if err != nil {
+		return Move{}, &MoveError{notation, ErrMalformedMove}
+	}
+
+	promotion := None
+	if len(notation) == 5 {
+		promotion = promotionFlag(notation[4] - ('a' - 'A'))
+		if promotion == None {
+			return Move{}, &MoveError{notation, ErrMalformedMove}
+		}
+	}
+
+	// An ordinary king move takes priority over castling given by the king's destination, which only conflict in Chess960
+	var castlingMove Move
+	castlingMatches := 0
+	for _, move := range legalMoves {
+		switch {
+		case move.IsCastle():
+			if move.Start == start && promotion == None && (move.End == end || castlingKingDestination(move) == end) {
+				castlingMove = move
+				castlingMatches++
+			}
+		case move.Start == start && move.End == end && move.Flag == promotion:
+			return move, nil
+		case move.Start == start && move.End == end && move.IsPromotion() && promotion == None:
+			return Move{}, &MoveError{notation, ErrMalformedMove}
+		}
+	}
+	if castlingMatches == 1 {
+		return castlingMove, nil
+	}
+
+	piece := state.Board.GetSquare(start)
+	if piece != EmptySquare && pieceColor(piece) != state.ActiveColor {
+		return Move{}, &MoveError{notation, ErrWrongSide}
+	}
+	return Move{}, &MoveError{notation, ErrIllegalMove}
+}
+
+// parseUCIDrop returns the legal drop given by the upper case piece and the square, such as N@f3.
+func parseUCIDrop(notation string, legalMoves []Move) (Move, error) {
+	end, err := stringToPosition(notation[2:])
+	if err != nil || strings.IndexByte("PNBRQ", notation[0]) < 0 {
+		return Move{}, &MoveError{notation, ErrMalformedMove}
+	}
+
+	piece := fenPieceToWhitePiece(notation[0])
+	for _, move := range legalMoves {
+		if move.IsDrop() && move.End == end && getWhitePiece(move.Piece) == piece {
+			return move, nil
+		}
+	}
+	return Move{}, &MoveError{notation, ErrIllegalMove}
+}
+
+// castlingKingDestination returns the square the king finishes on for a castling move.
+func castlingKingDestination(move Move) Position {
+	if move.Flag == QueenSideCastle {
+		return Position{move.Start.X, 2}
+	}
+	return Position{move.Start.X, 6}
+}
@@ -0,0 +1,81 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUCINotationRoundTrip(t *testing.T) {
+	for _, state := range []State{InitialiseState(), getKiwipete(), getPosition5()} {
+		moves, err := state.GenerateAllMoves()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		for _, move := range moves {
+			for _, kingTakesRook := range [2]bool{false, true} {
+				notation := move.ToUCINotation(kingTakesRook)
+				parsed, err := notation.ToMove(state)
+				if err != nil {
+					t.Errorf("%s in %s: %v", notation, state.FEN(), err)
+					continue
+				}
+				if parsed != move {
+					t.Errorf("%s did not round trip: expected=%+v; actual=%+v", notation, move, parsed)
+				}
+			}
+		}
+	}
+}
+
+func TestUCINotation(t *testing.T) {
+	tests := []struct {
+		fen           string
+		move          AlgebraicNotation
+		uci           UCINotation
+		kingTakesRook bool
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "Nf3", "g1f3", false},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O", "e1g1", false},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O", "e1h1", true},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "O-O-O", "e8c8", false},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "O-O-O", "e8a8", true},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=N", "a7a8n", false},
+		// A Chess960 king already on its castling square castles onto its own square in standard UCI
+		{"4k3/8/8/8/8/8/8/6KR w H - 0 1", "O-O", "g1g1", false},
+		{"4k3/8/8/8/8/8/8/6KR w H - 0 1", "O-O", "g1h1", true},
+		{"4k3/8/8/8/8/8/8/5RK1 w F - 0 1", "O-O-O", "g1c1", false},
+		{"4k3/8/8/8/8/8/8/5RK1 w F - 0 1", "O-O-O", "g1f1", true},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+
+		move, err := test.move.ToMove(state)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if uci := move.ToUCINotation(test.kingTakesRook); uci != test.uci {
+			t.Errorf("incorrect UCI notation for %s in %s: expected=%s; actual=%s", test.move, test.fen, test.uci, uci)
+		}
+
+		parsed, err := test.uci.ToMove(state)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if parsed != move {
+			t.Errorf("incorrect move for %s in %s: expected=%+v; actual=%+v", test.uci, test.fen, move, parsed)
+		}
+	}
+
+	_, err := UCINotation("e2e5").ToMove(InitialiseState())
+	if !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("expected an illegal move error: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package chess
+
+import "fmt"
+
+// ValidationProblemKind categorises the ways in which a position can be illegal.
+type ValidationProblemKind uint8
+
+const (
+	MissingKing ValidationProblemKind = iota
+	TooManyKings
+	TooManyPawns
+	TooManyPieces
+	PawnOnBackRank
+	InvalidCastlingRights
+	InvalidEnPassantSquare
+	InactiveColorInCheck
+	// TooManyPromotedPieces is when a side has more pieces beyond its starting set than it has missing pawns to have promoted.
+	TooManyPromotedPieces
+	KingsAdjacent
+	// TooManyCheckers is when the side to move is in check from more than two pieces, which no single move can give.
+	TooManyCheckers
+	// ImpossibleCheck is when the side to move is in check from two pieces on opposite sides of its king along the same line,
+	// as no single move can have given both checks.
+	ImpossibleCheck
+)
+
+// ValidationProblem describes a single reason why a position is illegal.
+// Position is set when the problem relates to a particular square.
+type ValidationProblem struct {
+	Kind     ValidationProblemKind
+	Color    Color
+	Position PositionOpt
+}
+
+// Error returns a human readable description of the problem.
+func (problem ValidationProblem) Error() string {
+	color := "white"
+	if problem.Color == Black {
+		color = "black"
+	}
+
+	square := ""
+	if problem.Position.Ok {
+		square, _ = positionToString(problem.Position.Position)
+	}
+
+	switch problem.Kind {
+	case MissingKing:
+		return fmt.Sprintf("%s has no king", color)
+	case TooManyKings:
+		return fmt.Sprintf("%s has more than one king", color)
+	case TooManyPawns:
+		return fmt.Sprintf("%s has more than 8 pawns", color)
+	case TooManyPieces:
+		return fmt.Sprintf("%s has more than 16 pieces", color)
+	case PawnOnBackRank:
+		return fmt.Sprintf("%s has a pawn on %s", color, square)
+	case InvalidCastlingRights:
+		return fmt.Sprintf("%s has castling rights but no rook or king on %s", color, square)
+	case InvalidEnPassantSquare:
+		return fmt.Sprintf("en passant square %s is impossible", square)
+	case InactiveColorInCheck:
+		return fmt.Sprintf("%s is in check but it is not their turn", color)
+	case TooManyPromotedPieces:
+		return fmt.Sprintf("%s has more promoted pieces than missing pawns", color)
+	case KingsAdjacent:
+		return "the kings are next to each other"
+	case TooManyCheckers:
+		return fmt.Sprintf("%s is in check from more than two pieces", color)
+	case ImpossibleCheck:
+		return fmt.Sprintf("%s is in check from two pieces in line with the king", color)
+	}
+	return "unknown problem"
+}
+
+// Validate checks that the state is a legal chess position, returning every problem found.
+// An empty result means the position is legal.
+func (state *State) Validate() []ValidationProblem {
+	problems := []ValidationProblem{}
+
+	// Captured pieces change sides when they can be dropped, so the number of pawns and pieces is only limited without drops
+	rules := state.GetVariant().Rules()
+	for _, color := range [2]Color{White, Black} {
+		problems = append(problems, state.Board.validateMaterial(color, !rules.Drops, !rules.OrdinaryKing)...)
+	}
+	problems = append(problems, state.validateCastlingRights()...)
+	problems = append(problems, state.validateEnPassantSquare()...)
+
+	problems = append(problems, state.validateChecks()...)
+
+	return problems
+}
+
+// Repair removes any castling rights and en passant square which are impossible in the position.
+// The problems which could not be repaired are returned.
+func (state *State) Repair() []ValidationProblem {
+	for _, color := range [2]Color{White, Black} {
+		for _, kingSide := range [2]bool{true, false} {
+			if !state.castlingRightIsValid(color, kingSide) {
+				state.CastlingRights.setCastlingRight(color, kingSide, false)
+			}
+		}
+	}
+
+	if len(state.validateEnPassantSquare()) > 0 {
+		state.EnPassantPosition = PositionOpt{Ok: false}
+	}
+	state.Hash = state.ComputeHash()
+
+	return state.Validate()
+}
+
+// validateMaterial checks the number of kings of the given color, that no pawn is on the back rank,
+// and that there are no more pieces beyond the starting set than the missing pawns could have promoted to.
+// The number of pawns and pieces are only checked if limitMaterial is set, as captured pieces change sides in variants with pockets,
+// and the number of kings is only checked if royalKings is set, as any number are allowed where the king is an ordinary piece.
+func (board *Board) validateMaterial(color Color, limitMaterial, royalKings bool) (problems []ValidationProblem) {
+	for pawns := board.Pieces(colorPiece(WhitePawn, color)) & (rank1 | rank8); pawns != 0; {
+		problems = append(problems, ValidationProblem{PawnOnBackRank, color, PositionOpt{pawns.popSquare().Position(), true}})
+	}
+
+	kings := board.Pieces(colorPiece(WhiteKing, color)).Count()
+	if royalKings && kings == 0 {
+		problems = append(problems, ValidationProblem{MissingKing, color, PositionOpt{Ok: false}})
+	} else if royalKings && kings > 1 {
+		problems = append(problems, ValidationProblem{TooManyKings, color, PositionOpt{Ok: false}})
+	}
+	if !limitMaterial {
+		return
+	}
+
+	material := board.countMaterial(color)
+	switch {
+	case material.pawns > 8:
+		problems = append(problems, ValidationProblem{TooManyPawns, color, PositionOpt{Ok: false}})
+	case board.ColorPieces(color).Count() > 16:
+		problems = append(problems, ValidationProblem{TooManyPieces, color, PositionOpt{Ok: false}})
+	default:
+		// Each piece beyond a queen, two rooks, two knights and a bishop of each square color must have been a pawn
+		promoted := max(material.queens-1, 0) + max(material.rooks-2, 0) + max(material.knights-2, 0) +
+			max(material.lightBishops-1, 0) + max(material.darkBishops-1, 0)
+		if !royalKings {
+			promoted += max(kings-1, 0)
+		}
+		if material.pawns+promoted > 8 {
+			problems = append(problems, ValidationProblem{TooManyPromotedPieces, color, PositionOpt{Ok: false}})
+		}
+	}
+
+	return
+}
+
+// validateChecks checks that the side which has just moved has not left its king in check or next to the enemy king,
+// and that the checks on the side to move could have been given by a single move.
+// Checks are found under the rules of the variant, so they are not checked at all in variants without check.
+func (state *State) validateChecks() (problems []ValidationProblem) {
+	mover := !state.ActiveColor
+	occupied := state.Board.Occupied()
+	enemyKing := state.Board.Pieces(colorPiece(WhiteKing, state.ActiveColor))
+
+	kingPosition, err := state.Board.FindKing(mover)
+	if isInCheck, _ := state.IsInCheck(mover); err == nil && isInCheck {
+		attackers := state.Board.attackersTo(squareOf(kingPosition), occupied) & state.Board.ColorPieces(state.ActiveColor)
+		if attackers&enemyKing != 0 {
+			problems = append(problems, ValidationProblem{KingsAdjacent, mover, PositionOpt{kingPosition, true}})
+		}
+		if attackers&^enemyKing != 0 {
+			problems = append(problems, ValidationProblem{InactiveColorInCheck, mover, PositionOpt{kingPosition, true}})
+		}
+	}
+
+	kingPosition, err = state.Board.FindKing(state.ActiveColor)
+	if isInCheck, _ := state.IsInCheck(state.ActiveColor); err != nil || !isInCheck {
+		return
+	}
+	checkers := state.Board.attackersTo(squareOf(kingPosition), occupied) & state.Board.ColorPieces(mover) &^
+		state.Board.Pieces(colorPiece(WhiteKing, mover))
+	switch checkers.Count() {
+	case 0, 1:
+	case 2:
+		first, second := checkers.popSquare().Position(), checkers.popSquare().Position()
+		if areInLineOnOppositeSides(kingPosition, first, second) {
+			problems = append(problems, ValidationProblem{ImpossibleCheck, state.ActiveColor, PositionOpt{kingPosition, true}})
+		}
+	default:
+		problems = append(problems, ValidationProblem{TooManyCheckers, state.ActiveColor, PositionOpt{kingPosition, true}})
+	}
+
+	return
+}
+
+// areInLineOnOppositeSides returns whether the two positions lie on the same rank, file or diagonal through the center, one on each side of it.
+func areInLineOnOppositeSides(center, first, second Position) bool {
+	direction := func(position Position) (Position, bool) {
+		x, y := position.X-center.X, position.Y-center.Y
+		if x != 0 && y != 0 && x != y && x != -y {
+			return Position{}, false
+		}
+		return Position{sign(x), sign(y)}, true
+	}
+
+	firstDirection, firstOk := direction(first)
+	secondDirection, secondOk := direction(second)
+	return firstOk && secondOk && firstDirection == (Position{-secondDirection.X, -secondDirection.Y})
+}
+
+// validateCastlingRights checks that the king and rook are on their starting squares for each castling right.
+// The position of the rook is given for each invalid right.
+func (state *State) validateCastlingRights() (problems []ValidationProblem) {
+	for _, color := range [2]Color{White, Black} {
+		for _, kingSide := range [2]bool{true, false} {
+			if state.castlingRightIsValid(color, kingSide) {
+				continue
+			}
+
+			rookPosition := state.CastlingRookPosition(color, kingSide)
+			problems = append(problems, ValidationProblem{InvalidCastlingRights, color, PositionOpt{rookPosition, true}})
+		}
+	}
+
+	return
+}
+
+// castlingRightIsValid returns whether the given castling right is absent or has the king and rook on their castling squares, with the rook on the correct side of the king.
+func (state *State) castlingRightIsValid(color Color, kingSide bool) bool {
+	if !state.CastlingRights.CanCastle(color, kingSide) {
+		return true
+	}
+
+	king, rook := WhiteKing, WhiteRook
+	if color == Black {
+		king, rook = BlackKing, BlackRook
+	}
+
+	rookPosition := state.CastlingRookPosition(color, kingSide)
+	kingPosition := state.CastlingKingPosition(color)
+	return state.Board.GetSquare(kingPosition) == king &&
+		state.Board.GetSquare(rookPosition) == rook &&
+		(rookPosition.Y > kingPosition.Y) == kingSide
+}
+
+// validateEnPassantSquare checks that the en passant square could have been left by a double pawn push on the previous move.
+func (state *State) validateEnPassantSquare() []ValidationProblem {
+	if !state.EnPassantPosition.Ok {
+		return nil
+	}
+
+	// The pawn which has just moved belongs to the inactive color
+	position := state.EnPassantPosition.Position
+	rank, direction, pawn := int8(2), int8(1), BlackPawn
+	if state.ActiveColor == Black {
+		rank, direction, pawn = 5, -1, WhitePawn
+	}
+
+	if position.X != rank ||
+		state.Board.GetSquare(position) != EmptySquare ||
+		state.Board.GetSquare(Position{position.X - direction, position.Y}) != EmptySquare ||
+		state.Board.GetSquare(Position{position.X + direction, position.Y}) != pawn {
+
+		return []ValidationProblem{{InvalidEnPassantSquare, !state.ActiveColor, state.EnPassantPosition}}
+	}
+
+	return nil
+}
+
+// sign returns -1, 0 or 1 for a negative, zero or positive value.
+func sign(value int8) int8 {
+	switch {
+	case value < 0:
+		return -1
+	case value > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package chess
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected []ValidationProblemKind
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []ValidationProblemKind{}},
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", []ValidationProblemKind{}},
+		{"8/8/8/8/8/8/8/4K3 w - - 0 1", []ValidationProblemKind{MissingKing}},
+		{"4k3/8/8/8/8/8/8/K3K2K w - - 0 1", []ValidationProblemKind{TooManyKings}},
+		{"4k3/8/8/8/8/8/8/P3K3 w - - 0 1", []ValidationProblemKind{PawnOnBackRank}},
+		{"4k3/pppppppp/p7/8/8/8/8/4K3 w - - 0 1", []ValidationProblemKind{TooManyPawns}},
+		{"rnbqkbnr/pppppppp/n7/8/8/8/8/4K3 w - - 0 1", []ValidationProblemKind{TooManyPieces}},
+		{"4k3/8/8/8/8/8/8/4K3 w KQ - 0 1", []ValidationProblemKind{InvalidCastlingRights, InvalidCastlingRights}},
+		{"r3k2r/8/8/8/8/8/8/R4K1R w KQkq - 0 1", []ValidationProblemKind{InvalidCastlingRights, InvalidCastlingRights}},
+		{"r3k2r/8/8/8/8/8/8/R4K1R w HAkq - 0 1", []ValidationProblemKind{}},
+		{"r3k3/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []ValidationProblemKind{InvalidCastlingRights}},
+		{"4k3/8/8/8/4P3/8/8/4K3 b - d3 0 1", []ValidationProblemKind{InvalidEnPassantSquare}},
+		{"4k3/8/8/8/4P3/8/8/4K3 w - e3 0 1", []ValidationProblemKind{InvalidEnPassantSquare}},
+		{"4k3/8/8/8/8/8/8/4K2r b - - 0 1", []ValidationProblemKind{InactiveColorInCheck}},
+		{"4k3/8/8/8/8/8/PPPPPPPP/QQ2K3 w - - 0 1", []ValidationProblemKind{TooManyPromotedPieces}},
+		{"4k3/8/8/8/8/8/PPPPPPP1/QQ2K3 w - - 0 1", []ValidationProblemKind{}},
+		{"4k3/8/8/8/8/8/PPPPPPP1/1B1BK3 w - - 0 1", []ValidationProblemKind{}},
+		{"4k3/8/8/8/8/8/PPPPPPPP/B1B1K3 w - - 0 1", []ValidationProblemKind{TooManyPromotedPieces}},
+		{"8/8/8/8/8/8/3k4/4K3 w - - 0 1", []ValidationProblemKind{KingsAdjacent}},
+		{"8/8/8/8/8/3k4/4K2r/8 b - - 0 1", []ValidationProblemKind{KingsAdjacent, InactiveColorInCheck}},
+		{"4k3/8/8/8/1b6/5n2/8/4K2r w - - 0 1", []ValidationProblemKind{TooManyCheckers}},
+		{"4k3/8/8/8/8/8/8/r3K2r w - - 0 1", []ValidationProblemKind{ImpossibleCheck}},
+		{"4k3/8/8/8/1b6/5n2/8/4K3 w - - 0 1", []ValidationProblemKind{}},
+		{"4k3/8/8/8/8/8/3p4/4K2r w - - 0 1", []ValidationProblemKind{}},
+	}
+
+	for _, test := range tests {
+		state, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		problems := state.Validate()
+		if len(problems) != len(test.expected) {
+			t.Errorf("unexpected problems for %s: expected=%v; actual=%v", test.fen, test.expected, problems)
+			continue
+		}
+
+		for i, problem := range problems {
+			if problem.Kind != test.expected[i] {
+				t.Errorf("unexpected problem for %s: expected=%d; actual=%s", test.fen, test.expected[i], problem.Error())
+			}
+		}
+	}
+}
+
+func TestValidateAdjacentKingsInVariants(t *testing.T) {
+	// The kings may stand next to each other in Atomic, where neither can capture, and in Antichess, where there is no check
+	for _, variant := range []Variant{Atomic, Antichess} {
+		state, err := ParseVariantFEN("8/8/8/8/8/3k4/4K3/8 w - - 0 1", variant)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if problems := state.Validate(); len(problems) != 0 {
+			t.Errorf("unexpected problems in %s: %v", variant.Name(), problems)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	state, err := ParseFEN("4k2r/8/8/8/4P3/8/8/R3K3 w KQkq d6 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	problems := state.Repair()
+	if len(problems) != 0 {
+		t.Errorf("unexpected problems after repair: %v", problems)
+	}
+
+	expected := "4k2r/8/8/8/4P3/8/8/R3K3 w Qk - 0 1"
+	if state.FEN() != expected {
+		t.Errorf("incorrect repaired state: expected=%s; actual=%s", expected, state.FEN())
+	}
+}
@@ -0,0 +1,292 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Variant alters the rules of standard chess, adding win conditions and any extra state the variant needs.
+// The variant of a position is held in State.Variant, where a nil variant is treated as Standard.
+//
+// A variant only needs to change the methods whose rules differ from standard chess, so the variants in this package embed
+// the standard rules, and a variant in another package can do the same by embedding Variant in its type and setting it to Standard.
+type Variant interface {
+	// Name returns the name of the variant, as used in the Variant tag of a PGN file.
+	Name() string
+	// Outcome returns the result of the state with the given legal moves under the variant's own win conditions, which are checked before the standard rules.
+	// An ongoing outcome is returned if the variant's rules do not end the game.
+	Outcome(state *State, legalMoves []Move) Outcome
+	// HasInsufficientMaterialToWin returns whether the given color cannot win by any sequence of legal moves.
+	HasInsufficientMaterialToWin(state *State, color Color) bool
+	// Rules returns the changes the variant makes to how moves are played and written.
+	Rules() Rules
+}
+
+// Rules describes the changes a variant makes to how moves are played and written, where the zero value is the standard rules.
+// State reads them from the variant whenever it generates, makes or unmakes a move, so any combination of them can be used by a new variant.
+type Rules struct {
+	// Drops is whether captured pieces go into the capturer's pocket and may be dropped back onto the board as their own, as in Crazyhouse.
+	// The pockets are given in brackets after the board in FEN, such as [Nbp], with promoted pieces followed by a ~.
+	Drops bool
+	// CountChecks is whether the checks given by each side are counted in State.Checks, as in Three-check.
+	// The counts are given as a seventh FEN field, such as +1+0 after white has given one check.
+	CountChecks bool
+	// Explosions is whether every capture explodes the capturing piece along with every piece other than a pawn next to the capture, as in Atomic.
+	// A king cannot capture, and is never in check while it is next to the enemy king.
+	Explosions bool
+	// OrdinaryKing is whether the king is an ordinary piece which can be captured and promoted to, as in Antichess.
+	// There is no check or castling, so castling rights must be given as - in FEN, and captures are compulsory.
+	OrdinaryKing bool
+}
+
+var (
+	// Standard is the standard rules of chess, which are also used for Chess960.
+	Standard Variant = standardVariant{}
+	// ThreeCheck is won by checkmate or by giving check three times.
+	ThreeCheck Variant = threeCheckVariant{}
+	// KingOfTheHill is won by checkmate or by moving the king to one of the four central squares.
+	KingOfTheHill Variant = kingOfTheHillVariant{}
+	// Crazyhouse lets a player drop the pieces they have captured back onto the board as their own.
+	Crazyhouse Variant = crazyhouseVariant{}
+	// Atomic is won by checkmate or by exploding the enemy king, as every capture explodes the pieces other than pawns around it.
+	Atomic Variant = atomicVariant{}
+	// Antichess is won by losing every piece or by being stalemated, as captures are compulsory and there is no check.
+	Antichess Variant = antichessVariant{}
+)
+
+// Variants lists every supported variant.
+var Variants = []Variant{Standard, ThreeCheck, KingOfTheHill, Crazyhouse, Atomic, Antichess}
+
+// threeCheckWinningChecks is the number of checks which wins a game of Three-check.
+const threeCheckWinningChecks = 3
+
+// hillSquares are the central squares d4, e4, d5 and e5, which win a game of King of the Hill.
+var hillSquares = PositionBitboard(Position{3, 3}) | PositionBitboard(Position{3, 4}) |
+	PositionBitboard(Position{4, 3}) | PositionBitboard(Position{4, 4})
+
+// CheckCounts holds the number of checks each side has given, which is only tracked in variants such as Three-check.
+type CheckCounts struct {
+	White int
+	Black int
+}
+
+// ParseVariant returns the variant with the given name, ignoring case, spaces and hyphens, so that "Three-check" and "threecheck" are the same.
+func ParseVariant(name string) (Variant, error) {
+	normalise := func(name string) string {
+		return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(name))
+	}
+
+	for _, variant := range Variants {
+		if normalise(variant.Name()) == normalise(name) {
+			return variant, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown variant %q", name)
+}
+
+// GetVariant returns the variant of the state, which is Standard if none has been set.
+func (state *State) GetVariant() Variant {
+	if state.Variant == nil {
+		return Standard
+	}
+	return state.Variant
+}
+
+// SetVariant changes the variant of the state, clearing any variant specific state such as check counts and pockets.
+// Castling rights are removed in variants without castling.
+func (state *State) SetVariant(variant Variant) {
+	state.Variant = variant
+	if variant.Rules().OrdinaryKing {
+		state.CastlingRights = CastlingRights{}
+	}
+	state.Checks = CheckCounts{}
+	state.Pockets = Pockets{}
+	state.Promoted = 0
+	state.Hash = state.ComputeHash()
+}
+
+// generateMoves replaces the contents of the list with the legal moves of the given stage under the rules of the state's variant.
+func (state *State) generateMoves(moves *MoveList, stage MoveStage) error {
+	rules := state.GetVariant().Rules()
+	switch {
+	case rules.Explosions:
+		return state.generateAtomicMoves(moves, stage, rules.Drops)
+	case rules.OrdinaryKing:
+		return state.generateAntichessMoves(moves, stage, rules.Drops)
+	}
+	return state.generateStandardMoves(moves, stage, rules.Drops)
+}
+
+// IsInCheck returns whether the given color's king is in check under the rules of the state's variant.
+// In Atomic a king is never in check while it is next to the enemy king, and a king which has exploded is in check so that the move which exploded it is written as mate.
+// In Antichess there is no check.
+func (state *State) IsInCheck(color Color) (bool, error) {
+	rules := state.GetVariant().Rules()
+	switch {
+	case rules.OrdinaryKing:
+		return false, nil
+	case rules.Explosions:
+		if state.Board.Pieces(colorPiece(WhiteKing, color)) == 0 {
+			return true, nil
+		}
+		return state.Board.isInAtomicCheck(color), nil
+	}
+	return state.Board.IsInCheck(color)
+}
+
+// changedSquares returns every square whose contents the move changes under the rules of the state's variant, so that their keys can be replaced in the hash.
+func (state *State) changedSquares(move Move) Bitboard {
+	squares := moveSquares(move)
+	if state.GetVariant().Rules().Explosions && move.IsCapture() {
+		// Any of the pieces around the capture may explode
+		squares |= kingAttacks[squareOf(move.End)]
+	}
+	return squares
+}
+
+// makeMove makes the move in the record on the board under the rules of the state's variant, along with any changes to the variant's own state.
+// It is called by DoMove after the castling rights, en passant square and clocks have been updated, while the moving side is still active,
+// and returns the record with anything else needed to revert the move, such as the pieces removed by an explosion.
+func (state *State) makeMove(record UndoRecord) UndoRecord {
+	rules := state.GetVariant().Rules()
+	move := record.Move
+	mover := state.ActiveColor
+
+	if rules.Drops {
+		state.updatePockets(move)
+	}
+
+	if rules.Explosions {
+		record.Explosion = state.Board.DoAtomicMove(move)
+		if move.IsCapture() {
+			// The kings and rooks which exploded can no longer castle
+			for _, color := range [2]Color{White, Black} {
+				for _, kingSide := range [2]bool{true, false} {
+					if !state.castlingRightIsValid(color, kingSide) {
+						state.CastlingRights.setCastlingRight(color, kingSide, false)
+					}
+				}
+			}
+		}
+	} else {
+		state.Board.DoMove(move)
+	}
+
+	if rules.CountChecks {
+		king := state.Board.Pieces(colorPiece(WhiteKing, !mover))
+		if king != 0 && state.Board.attackersTo(king.firstSquare(), state.Board.Occupied())&state.Board.ColorPieces(mover) != 0 {
+			state.Checks.increment(mover)
+		}
+	}
+	return record
+}
+
+// unmakeMove reverts the changes makeMove made to the board.
+// The rest of the state, including the check counts, pockets and promoted pieces, is restored from the record by UndoMove.
+func (state *State) unmakeMove(record UndoRecord) {
+	if state.GetVariant().Rules().Explosions {
+		state.Board.UndoAtomicMove(record.Move, record.Explosion)
+	} else {
+		state.Board.UndoMove(record.Move)
+	}
+}
+
+// variantHashKey returns the combined Zobrist key for the state's own variant state, such as the check counts or pockets.
+func (state *State) variantHashKey() uint64 {
+	rules := state.GetVariant().Rules()
+	key := uint64(0)
+	if rules.CountChecks {
+		key ^= state.Checks.zobristKey()
+	}
+	if rules.Drops {
+		key ^= state.zobristPocketsKey()
+	}
+	return key
+}
+
+// Get returns the number of checks given by the given color.
+func (checks CheckCounts) Get(color Color) int {
+	if color == Black {
+		return checks.Black
+	}
+	return checks.White
+}
+
+// increment adds a check given by the given color.
+func (checks *CheckCounts) increment(color Color) {
+	if color == Black {
+		checks.Black++
+	} else {
+		checks.White++
+	}
+}
+
+// standardVariant implements the standard rules of chess, which the other variants embed for the methods they do not change.
+type standardVariant struct{}
+
+func (standardVariant) Name() string {
+	return "Standard"
+}
+
+func (standardVariant) Outcome(state *State, legalMoves []Move) Outcome {
+	return Outcome{Ongoing, Unterminated}
+}
+
+func (standardVariant) HasInsufficientMaterialToWin(state *State, color Color) bool {
+	return state.Board.HasInsufficientMaterialToWin(color)
+}
+
+func (standardVariant) Rules() Rules {
+	return Rules{}
+}
+
+type threeCheckVariant struct {
+	standardVariant
+}
+
+func (threeCheckVariant) Name() string {
+	return "Three-check"
+}
+
+func (threeCheckVariant) Outcome(state *State, legalMoves []Move) Outcome {
+	// Only the side which has just moved can have given a winning check
+	mover := !state.ActiveColor
+	if state.Checks.Get(mover) >= threeCheckWinningChecks {
+		return winFor(mover, ThreeChecks)
+	}
+	if state.Checks.Get(!mover) >= threeCheckWinningChecks {
+		return winFor(!mover, ThreeChecks)
+	}
+	return Outcome{Ongoing, Unterminated}
+}
+
+// HasInsufficientMaterialToWin returns whether the color has only its king, as any other piece may be able to give check.
+func (threeCheckVariant) HasInsufficientMaterialToWin(state *State, color Color) bool {
+	return state.Board.ColorPieces(color) == state.Board.Pieces(colorPiece(WhiteKing, color))
+}
+
+func (threeCheckVariant) Rules() Rules {
+	return Rules{CountChecks: true}
+}
+
+type kingOfTheHillVariant struct {
+	standardVariant
+}
+
+func (kingOfTheHillVariant) Name() string {
+	return "King of the Hill"
+}
+
+func (kingOfTheHillVariant) Outcome(state *State, legalMoves []Move) Outcome {
+	for _, color := range [2]Color{!state.ActiveColor, state.ActiveColor} {
+		if state.Board.Pieces(colorPiece(WhiteKing, color))&hillSquares != 0 {
+			return winFor(color, KingOfTheHillReached)
+		}
+	}
+	return Outcome{Ongoing, Unterminated}
+}
+
+// HasInsufficientMaterialToWin always returns false, as even a lone king may reach the hill.
+func (kingOfTheHillVariant) HasInsufficientMaterialToWin(state *State, color Color) bool {
+	return false
+}
@@ -0,0 +1,209 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVariant(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant Variant
+	}{
+		{"Standard", Standard},
+		{"Three-check", ThreeCheck},
+		{"threecheck", ThreeCheck},
+		{"King of the Hill", KingOfTheHill},
+		{"kingOfTheHill", KingOfTheHill},
+	}
+
+	for _, test := range tests {
+		variant, err := ParseVariant(test.name)
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if variant != test.variant {
+			t.Errorf("incorrect variant for %s: expected=%s; actual=%s", test.name, test.variant.Name(), variant.Name())
+		}
+	}
+
+	if _, err := ParseVariant("Bughouse"); err == nil {
+		t.Errorf("expected an error for an unknown variant")
+	}
+}
+
+func TestThreeCheckFEN(t *testing.T) {
+	fen := "rnbqkbnr/ppppp2p/5p2/6pQ/4P3/8/PPPP1PPP/RNB1KBNR b KQkq - 1 3 +1+0"
+	state, err := ParseVariantFEN(fen, ThreeCheck)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if state.Checks != (CheckCounts{1, 0}) {
+		t.Errorf("incorrect checks: %+v", state.Checks)
+	}
+	if state.FEN() != fen {
+		t.Errorf("FEN did not round trip: expected=%s; actual=%s", fen, state.FEN())
+	}
+
+	withoutChecks, err := ParseVariantFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", ThreeCheck)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if withoutChecks.Checks != (CheckCounts{}) {
+		t.Errorf("checks should default to zero: %+v", withoutChecks.Checks)
+	}
+
+	for _, invalid := range []string{"+1", "+1+4", "1+1", "+a+0"} {
+		_, err := ParseVariantFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 "+invalid, ThreeCheck)
+		var fenError *FENError
+		if !errors.As(err, &fenError) || fenError.Column != 58 {
+			t.Errorf("expected FEN error at column 58 for check counts %s: %v", invalid, err)
+		}
+	}
+
+	if _, err := ParseFEN(fen); err == nil {
+		t.Errorf("check counts should not be accepted in standard chess")
+	}
+}
+
+func TestThreeCheckCountsChecks(t *testing.T) {
+	state := InitialiseState()
+	state.SetVariant(ThreeCheck)
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, algebraic := range []AlgebraicNotation{"e4", "f6", "Qh5+", "g6", "Qxg6+", "hxg6", "Bd3"} {
+		doAlgebraicMove(t, &game, algebraic)
+	}
+	if game.State.Checks != (CheckCounts{2, 0}) {
+		t.Errorf("incorrect checks: %+v", game.State.Checks)
+	}
+	if game.Outcome.IsOver() {
+		t.Errorf("game should not be over after two checks")
+	}
+
+	for i := 0; i < 3; i++ {
+		game.UndoMove()
+	}
+	if game.State.Checks != (CheckCounts{1, 0}) {
+		t.Errorf("checks were not restored by undoing moves: %+v", game.State.Checks)
+	}
+}
+
+func TestThreeCheckWin(t *testing.T) {
+	state, err := ParseVariantFEN("4k3/8/8/8/8/8/8/4K2R w - - 0 1 +2+0", ThreeCheck)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	doAlgebraicMove(t, &game, "Rh8+")
+	if game.Outcome != (Outcome{WhiteWins, ThreeChecks}) {
+		t.Errorf("incorrect outcome after the third check: %s", game.Outcome)
+	}
+
+	game.UndoMove()
+	if game.Outcome.IsOver() {
+		t.Errorf("outcome was not restored by undoing the third check")
+	}
+}
+
+func TestKingOfTheHill(t *testing.T) {
+	fen := "8/8/8/8/8/4K3/8/k7 w - - 0 1"
+	standardState, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	standardGame, err := InitialiseGameFromState(standardState)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if standardGame.Outcome != (Outcome{Draw, InsufficientMaterial}) {
+		t.Errorf("bare kings should be a draw in standard chess: %s", standardGame.Outcome)
+	}
+
+	state, err := ParseVariantFEN(fen, KingOfTheHill)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	game, err := InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.Outcome.IsOver() {
+		t.Errorf("bare kings should not be a draw in King of the Hill: %s", game.Outcome)
+	}
+
+	doAlgebraicMove(t, &game, "Ke4")
+	if game.Outcome != (Outcome{WhiteWins, KingOfTheHillReached}) {
+		t.Errorf("incorrect outcome after reaching the hill: %s", game.Outcome)
+	}
+}
+
+func TestThreeCheckHash(t *testing.T) {
+	state := getKiwipete()
+	state.SetVariant(ThreeCheck)
+	testHashAfterMoves(t, state, 3)
+
+	withCheck, err := ParseVariantFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 +1+0", ThreeCheck)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if withCheck.Hash == InitialiseState().Hash {
+		t.Errorf("checks given did not change the hash")
+	}
+}
+
+// checkhouseVariant is a variant built outside of the built-in variants by combining their rules, in which pieces are dropped as in Crazyhouse and checks are counted as in Three-check.
+type checkhouseVariant struct {
+	Variant
+}
+
+func (checkhouseVariant) Name() string {
+	return "Checkhouse"
+}
+
+func (checkhouseVariant) Rules() Rules {
+	return Rules{Drops: true, CountChecks: true}
+}
+
+func TestCombinedVariantRules(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/8/4K3[N] w - - 0 1 +0+0"
+	state, err := ParseVariantFEN(fen, checkhouseVariant{Standard})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(moves) != 5+62 {
+		t.Errorf("incorrect number of moves with a knight to drop: %d", len(moves))
+	}
+
+	before := state
+	undo := state.DoMove(Move{Position{2, 3}, Position{2, 3}, Drop, EmptySquare, WhiteKnight})
+	if state.FEN() != "4k3/8/3N4/8/8/8/8/4K3[] b - - 1 1 +1+0" || state.Hash != state.ComputeHash() {
+		t.Errorf("incorrect state after a checking drop: %s", state.FEN())
+	}
+	state.UndoMove(undo)
+	if state != before {
+		t.Errorf("incorrect state after undoing a drop: %s", state.FEN())
+	}
+
+	var list MoveList
+	allocations := testing.AllocsPerRun(10, func() {
+		state.GenerateAllMovesInto(&list)
+		state.UndoMove(state.DoMove(list.Get(0).ToMove()))
+	})
+	if allocations != 0 {
+		t.Errorf("move generation allocated %v times", allocations)
+	}
+}
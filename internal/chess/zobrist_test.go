@@ -0,0 +1,137 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestHashMatchesComputedHashAfterMoves(t *testing.T) {
+	chess960State, err := InitialiseChess960State(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, state := range []State{InitialiseState(), getPosition5(), getKiwipete(), getPosition3(), chess960State} {
+		testHashAfterMoves(t, state, 3)
+	}
+}
+
+func TestHashTransposition(t *testing.T) {
+	first := InitialiseState()
+	second := InitialiseState()
+
+	for _, move := range []string{"Nf3", "Nf6", "Nc3", "Nc6"} {
+		doStateAlgebraicMove(t, &first, move)
+	}
+	for _, move := range []string{"Nc3", "Nc6", "Nf3", "Nf6"} {
+		doStateAlgebraicMove(t, &second, move)
+	}
+
+	if first.Hash != second.Hash {
+		t.Errorf("transposed positions have different hashes: %x and %x", first.Hash, second.Hash)
+	}
+}
+
+func TestHashDistinguishesState(t *testing.T) {
+	fens := []string{
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQk - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQq - 0 1",
+		"r3k2r/8/8/8/3Pp3/8/8/R3K2R b KQkq d3 0 1",
+		"r3k2r/8/8/8/3Pp3/8/8/R3K2R b KQkq - 0 1",
+	}
+
+	hashes := make(map[uint64]string, len(fens))
+	for _, fen := range fens {
+		state, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if other, ok := hashes[state.Hash]; ok {
+			t.Errorf("%s has the same hash as %s", fen, other)
+		}
+		hashes[state.Hash] = fen
+	}
+}
+
+func TestHashIgnoresUncapturableEnPassantSquare(t *testing.T) {
+	withEnPassant, err := ParseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	withoutEnPassant, err := ParseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if withEnPassant.Hash != withoutEnPassant.Hash {
+		t.Errorf("en passant square changed the hash although no pawn can capture")
+	}
+}
+
+func testHashAfterMoves(t *testing.T, state State, depth int) {
+	if state.Hash != state.ComputeHash() {
+		t.Fatalf("hash does not match computed hash for %s", state.FEN())
+	}
+	if depth == 0 {
+		return
+	}
+
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, move := range moves {
+		hash := state.Hash
+
+		undo := state.DoMove(move)
+		testHashAfterMoves(t, state, depth-1)
+		state.UndoMove(undo)
+
+		if state.Hash != hash {
+			t.Fatalf("hash was not restored after undoing a move in %s", state.FEN())
+		}
+	}
+}
+
+func doStateAlgebraicMove(t *testing.T, state *State, notation string) {
+	move, err := AlgebraicNotation(notation).ToMove(*state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	state.DoMove(move)
+}
+
+// Reference:
+// http://hgm.nubati.net/book_format.html
+
+func TestHashMatchesPolyglot(t *testing.T) {
+	tests := []struct {
+		moves    []string
+		expected uint64
+	}{
+		{[]string{}, 0x463b96181691fc9c},
+		{[]string{"e4"}, 0x823c9b50fd114196},
+		{[]string{"e4", "d5"}, 0x0756b94461c50fb0},
+		{[]string{"e4", "d5", "e5"}, 0x662fafb965db29d4},
+		{[]string{"e4", "d5", "e5", "f5"}, 0x22a48b5a8e47ff78},
+		{[]string{"e4", "d5", "e5", "f5", "Ke2"}, 0x652a607ca3f242c1},
+		{[]string{"e4", "d5", "e5", "f5", "Ke2", "Kf7"}, 0x00fdd303c946bdd9},
+		{[]string{"a4", "b5", "h4", "b4", "c4"}, 0x3c8123ea7b067637},
+		{[]string{"a4", "b5", "h4", "b4", "c4", "bxc3", "Ra3"}, 0x5c3f9b829b279560},
+	}
+
+	for _, test := range tests {
+		state := InitialiseState()
+		for _, move := range test.moves {
+			doStateAlgebraicMove(t, &state, move)
+		}
+
+		if state.Hash != test.expected || state.ComputeHash() != test.expected {
+			t.Errorf("incorrect hash after %v: expected=%016x; actual=%016x", test.moves, test.expected, state.Hash)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package perft
+
+import "sync"
+
+// lockCount is the number of locks shared between the entries of a hash table, so that goroutines rarely wait on each other.
+const lockCount = 256
+
+// hashEntry is the number of leaf nodes below a position for a tree of the given depth.
+type hashEntry struct {
+	hash  uint64
+	depth int
+	nodes int
+}
+
+// hashTable stores subtree counts by Zobrist hash, always replacing the existing entry.
+// It is safe for concurrent use, and a nil table stores nothing.
+type hashTable struct {
+	entries []hashEntry
+	locks   [lockCount]sync.Mutex
+}
+
+func newHashTable(size int) *hashTable {
+	return &hashTable{entries: make([]hashEntry, size)}
+}
+
+// get returns the stored count for the position and depth, if there is one.
+func (table *hashTable) get(hash uint64, depth int) (int, bool) {
+	if table == nil {
+		return 0, false
+	}
+
+	index := hash % uint64(len(table.entries))
+	lock := &table.locks[index%lockCount]
+	lock.Lock()
+	entry := table.entries[index]
+	lock.Unlock()
+
+	// An empty entry has depth 0, which is never stored
+	if entry.hash != hash || entry.depth != depth {
+		return 0, false
+	}
+	return entry.nodes, true
+}
+
+// put stores the count for the position and depth.
+func (table *hashTable) put(hash uint64, depth int, nodes int) {
+	if table == nil {
+		return
+	}
+
+	index := hash % uint64(len(table.entries))
+	lock := &table.locks[index%lockCount]
+	lock.Lock()
+	table.entries[index] = hashEntry{hash, depth, nodes}
+	lock.Unlock()
+}
@@ -0,0 +1,124 @@
+package perft
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+// Options controls how the nodes of a perft search are counted.
+type Options struct {
+	// Goroutines is the number of goroutines the root moves are shared between, with 1 used if it is less than 1.
+	Goroutines int
+	// HashEntries is the number of subtree counts kept in a hash table, with no table used if it is 0.
+	HashEntries int
+}
+
+// ErrInvalidDepth is returned by Count and Divide for a depth of less than 1.
+var ErrInvalidDepth = errors.New("perft depth must be at least 1")
+
+// DivideResult is the number of leaf nodes below a single root move.
+type DivideResult struct {
+	Move  chess.Move
+	Nodes int
+}
+
+// Count returns the number of leaf nodes of the legal move tree of the given depth, which must be at least 1.
+func Count(state chess.State, depth int, options Options) (int, error) {
+	results, err := Divide(state, depth, options)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, result := range results {
+		total += result.Nodes
+	}
+	return total, nil
+}
+
+// Divide returns the number of leaf nodes below each legal move in the state, for a tree of the given depth.
+// The results are in the order the moves are generated, and the depth must be at least 1.
+func Divide(state chess.State, depth int, options Options) ([]DivideResult, error) {
+	if depth < 1 {
+		return nil, ErrInvalidDepth
+	}
+
+	moves, err := state.GenerateAllMoves()
+	if err != nil {
+		return nil, err
+	}
+
+	var table *hashTable
+	if options.HashEntries > 0 {
+		table = newHashTable(options.HashEntries)
+	}
+
+	goroutines := max(options.Goroutines, 1)
+	results := make([]DivideResult, len(moves))
+	errs := make([]error, len(moves))
+	indices := make(chan int)
+
+	var wait sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for index := range indices {
+				child := state
+				child.DoMove(moves[index])
+				nodes, err := count(&child, depth-1, table)
+				results[index] = DivideResult{moves[index], nodes}
+				errs[index] = err
+			}
+		}()
+	}
+
+	for i := range moves {
+		indices <- i
+	}
+	close(indices)
+	wait.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// count returns the number of leaf nodes of the tree of the given depth, using the hash table if it is not nil.
+func count(state *chess.State, depth int, table *hashTable) (int, error) {
+	if depth == 0 {
+		return 1, nil
+	}
+
+	var moves chess.MoveList
+	if err := state.GenerateAllMovesInto(&moves); err != nil {
+		return 0, err
+	}
+	if depth == 1 {
+		return moves.Len(), nil
+	}
+
+	if nodes, ok := table.get(state.Hash, depth); ok {
+		return nodes, nil
+	}
+
+	total := 0
+	for _, move := range moves.Moves() {
+		undo := state.DoMove(move.ToMove())
+		nodes, err := count(state, depth-1, table)
+		state.UndoMove(undo)
+
+		if err != nil {
+			return 0, err
+		}
+		total += nodes
+	}
+
+	table.put(state.Hash, depth, total)
+	return total, nil
+}
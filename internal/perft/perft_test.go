@@ -0,0 +1,101 @@
+package perft
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+const kiwipeteFEN = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+func TestCount(t *testing.T) {
+	state, err := chess.ParseFEN(kiwipeteFEN)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, options := range []Options{
+		{},
+		{Goroutines: 4},
+		{HashEntries: 1 << 16},
+		{Goroutines: 4, HashEntries: 1 << 10},
+	} {
+		nodes, err := Count(state, 4, options)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if nodes != 4085603 {
+			t.Errorf("incorrect node count with %+v: expected=%d; actual=%d", options, 4085603, nodes)
+		}
+	}
+}
+
+func TestInvalidDepth(t *testing.T) {
+	for _, depth := range []int{0, -1} {
+		if _, err := Count(chess.InitialiseState(), depth, Options{}); !errors.Is(err, ErrInvalidDepth) {
+			t.Errorf("expected an invalid depth error from Count for depth %d: %v", depth, err)
+		}
+		if _, err := Divide(chess.InitialiseState(), depth, Options{}); !errors.Is(err, ErrInvalidDepth) {
+			t.Errorf("expected an invalid depth error from Divide for depth %d: %v", depth, err)
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	results, err := Divide(chess.InitialiseState(), 3, Options{Goroutines: 2})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(results) != 20 {
+		t.Fatalf("expected 20 root moves but found %d", len(results))
+	}
+
+	total := 0
+	for _, result := range results {
+		total += result.Nodes
+	}
+	if total != 8902 {
+		t.Errorf("incorrect total: expected=%d; actual=%d", 8902, total)
+	}
+}
+
+func TestReadSuite(t *testing.T) {
+	suite := `# Starting position
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1; 3; 8902
+
+8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1;4;43238
+`
+	cases, err := ReadSuite(strings.NewReader(suite))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(cases) != 2 {
+		t.Fatalf("expected 2 cases but found %d", len(cases))
+	}
+
+	for _, suiteCase := range cases {
+		nodes, err := Count(suiteCase.State, suiteCase.Depth, Options{})
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if nodes != suiteCase.Expected {
+			t.Errorf("incorrect node count for %s: expected=%d; actual=%d", suiteCase.FEN, suiteCase.Expected, nodes)
+		}
+	}
+}
+
+func TestReadSuiteErrors(t *testing.T) {
+	for _, suite := range []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1;3",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0;3;8902",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1;x;8902",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1;3;-1",
+	} {
+		if _, err := ReadSuite(strings.NewReader(suite)); err == nil {
+			t.Errorf("expected an error reading %q", suite)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package perft
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+// SuiteCase is a position with the expected number of leaf nodes for a tree of the given depth.
+type SuiteCase struct {
+	FEN      string
+	State    chess.State
+	Depth    int
+	Expected int
+}
+
+// ReadSuite reads a perft suite with one case per line, written as FEN;depth;expected.
+// Blank lines and lines starting with # are ignored.
+func ReadSuite(reader io.Reader) ([]SuiteCase, error) {
+	cases := []SuiteCase{}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected FEN;depth;expected but found %d fields", lineNumber, len(fields))
+		}
+
+		fen := strings.TrimSpace(fields[0])
+		state, err := chess.ParseFEN(fen)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		depth, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil || depth < 1 {
+			return nil, fmt.Errorf("line %d: invalid depth %q", lineNumber, fields[1])
+		}
+
+		expected, err := strconv.Atoi(strings.TrimSpace(fields[2]))
+		if err != nil || expected < 0 {
+			return nil, fmt.Errorf("line %d: invalid expected node count %q", lineNumber, fields[2])
+		}
+
+		cases = append(cases, SuiteCase{fen, state, depth, expected})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
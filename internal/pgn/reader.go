@@ -0,0 +1,376 @@
+package pgn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+// SevenTagRoster lists the tags which every game in a PGN file should have, in the order they are written.
+var SevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// maxNAG is the largest numeric annotation glyph.
+const maxNAG = 255
+
+// Tag is a tag pair from the header of a game, such as [Event "Casual game"].
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Move is a move of a game along with the annotations which follow it.
+type Move struct {
+	Move chess.Move
+	// NAGs holds the numeric annotation glyphs of the move, with suffix annotations such as !? converted to their glyphs.
+	NAGs []int
+	// Comment holds the comments after the move, joined by spaces.
+	Comment string
+	// Variations holds the alternatives to the move, each of which starts from the position before it.
+	Variations []Variation
+}
+
+// Variation is a sequence of moves along with any comment made before the first of them.
+type Variation struct {
+	Comment string
+	Moves   []Move
+}
+
+// Game is a game read from a PGN file.
+type Game struct {
+	// Tags holds the tag pairs in the order they were given.
+	Tags []Tag
+	// Start is the position given by the FEN tag, or the starting position of the variant if there is none.
+	Start chess.State
+	// MainLine holds the moves of the game, with any variations attached to the moves they are alternatives to.
+	MainLine Variation
+	// Result is given by the game termination marker at the end of the moves.
+	Result chess.Result
+}
+
+// ParseError describes why PGN text could not be read, along with the 1-based line and column where the problem was found.
+// Err is set when the problem is an error from the chess package, such as a *chess.MoveError for an illegal move.
+type ParseError struct {
+	Line    int
+	Column  int
+	Message string
+	Err     error
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("invalid PGN at line %d, column %d: %s", err.Line, err.Column, err.Message)
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// Reader reads the games of a PGN file one at a time.
+type Reader struct {
+	scanner *scanner
+	// err is returned by every call to Read after an error other than a *ParseError, such as a failure of the underlying reader.
+	err error
+}
+
+// NewReader returns a Reader which reads games from the given reader.
+func NewReader(reader io.Reader) *Reader {
+	return &Reader{scanner: newScanner(reader)}
+}
+
+// ReadAll reads every game from the given reader.
+func ReadAll(reader io.Reader) ([]Game, error) {
+	games := []Game{}
+	pgnReader := NewReader(reader)
+	for {
+		game, err := pgnReader.Read()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+}
+
+// Read returns the next game, or io.EOF if there are no more games.
+// The moves are checked to be legal as they are read, so an illegal or ambiguous move is reported as a *ParseError wrapping a *chess.MoveError.
+// After a *ParseError the rest of the game is skipped up to the next [ at the start of a line, so the next call reads the following game.
+func (reader *Reader) Read() (Game, error) {
+	if reader.err != nil {
+		return Game{}, reader.err
+	}
+
+	game, err := reader.readGame()
+	var parseError *ParseError
+	if errors.As(err, &parseError) {
+		if err := reader.scanner.skipToTags(); err != nil {
+			reader.err = err
+		}
+		return Game{}, err
+	}
+	if err != nil {
+		reader.err = err
+		return Game{}, err
+	}
+	return game, nil
+}
+
+// Tag returns the value of the first tag with the given name.
+func (game *Game) Tag(name string) (string, bool) {
+	for _, tag := range game.Tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// Replay plays the main line from the starting position, returning the resulting chess.Game.
+func (game *Game) Replay() (chess.Game, error) {
+	chessGame, err := chess.InitialiseGameFromState(game.Start)
+	if err != nil {
+		return chess.Game{}, err
+	}
+
+	for _, move := range game.MainLine.Moves {
+		if err := chessGame.DoMove(move.Move); err != nil {
+			return chess.Game{}, err
+		}
+	}
+	return chessGame, nil
+}
+
+func (reader *Reader) readGame() (Game, error) {
+	token, err := reader.scanner.next()
+	if err != nil {
+		return Game{}, err
+	}
+	if token.kind == tokenEOF {
+		return Game{}, io.EOF
+	}
+	reader.scanner.unread(token)
+
+	game := Game{}
+	tagTokens, err := reader.readTags(&game)
+	if err != nil {
+		return Game{}, err
+	}
+
+	game.Start, err = getStartingState(&game, tagTokens)
+	if err != nil {
+		return Game{}, err
+	}
+
+	game.MainLine, game.Result, err = reader.readVariation(game.Start, false)
+	if err != nil {
+		return Game{}, err
+	}
+	return game, nil
+}
+
+// readTags reads the tag pairs at the start of a game, returning the token of each tag's value so that problems with it can be located.
+func (reader *Reader) readTags(game *Game) (map[string]token, error) {
+	game.Tags = []Tag{}
+	tagTokens := map[string]token{}
+
+	for {
+		token, err := reader.scanner.next()
+		if err != nil {
+			return nil, err
+		}
+		if token.kind != tokenOpenBracket {
+			reader.scanner.unread(token)
+			return tagTokens, nil
+		}
+
+		name, err := reader.expect(tokenSymbol, "tag name")
+		if err != nil {
+			return nil, err
+		}
+		value, err := reader.expect(tokenString, "tag value")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := reader.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+
+		game.Tags = append(game.Tags, Tag{name.value, value.value})
+		if _, ok := tagTokens[name.value]; !ok {
+			tagTokens[name.value] = value
+		}
+	}
+}
+
+// expect reads the next token, returning an error if it is not of the given kind.
+func (reader *Reader) expect(kind tokenKind, description string) (token, error) {
+	token, err := reader.scanner.next()
+	if err != nil {
+		return token, err
+	}
+	if token.kind != kind {
+		return token, &ParseError{token.line, token.column, "expected " + description, nil}
+	}
+	return token, nil
+}
+
+// getStartingState returns the position given by the Variant, SetUp and FEN tags.
+// Chess960 games are played under the standard rules from the position in the FEN tag, with KQkq read as X-FEN.
+func getStartingState(game *Game, tagTokens map[string]token) (chess.State, error) {
+	variant := chess.Standard
+	parseFEN := chess.ParseVariantFEN
+	if name, ok := game.Tag("Variant"); ok && strings.EqualFold(name, "Chess960") {
+		parseFEN = chess.ParseChess960FEN
+	} else if ok && !strings.EqualFold(name, "From Position") {
+		var err error
+		variant, err = chess.ParseVariant(name)
+		if err != nil {
+			token := tagTokens["Variant"]
+			return chess.State{}, &ParseError{token.line, token.column, err.Error(), err}
+		}
+	}
+
+	fen, hasFEN := game.Tag("FEN")
+	if setUp, ok := game.Tag("SetUp"); ok && setUp == "1" && !hasFEN {
+		token := tagTokens["SetUp"]
+		return chess.State{}, &ParseError{token.line, token.column, "SetUp is 1 but there is no FEN tag", nil}
+	}
+
+	if !hasFEN {
+		state := chess.InitialiseState()
+		state.SetVariant(variant)
+		return state, nil
+	}
+
+	state, err := parseFEN(fen, variant)
+	if err != nil {
+		token := tagTokens["FEN"]
+		return chess.State{}, &ParseError{token.line, token.column, err.Error(), err}
+	}
+	return state, nil
+}
+
+// readVariation reads moves from the given position until the end of the variation, or the game termination marker for the main line.
+func (reader *Reader) readVariation(state chess.State, nested bool) (Variation, chess.Result, error) {
+	variation := Variation{Moves: []Move{}}
+
+	// before is the position before the last move, which its variations start from
+	before := state
+	for {
+		token, err := reader.scanner.next()
+		if err != nil {
+			return Variation{}, chess.Ongoing, err
+		}
+
+		fail := func(format string, args ...interface{}) (Variation, chess.Result, error) {
+			return Variation{}, chess.Ongoing, &ParseError{token.line, token.column, fmt.Sprintf(format, args...), nil}
+		}
+		var last *Move
+		if len(variation.Moves) > 0 {
+			last = &variation.Moves[len(variation.Moves)-1]
+		}
+
+		switch token.kind {
+		case tokenEOF:
+			if nested {
+				return fail("unterminated variation")
+			}
+			return fail("missing game termination marker")
+		case tokenPeriod:
+			continue
+		case tokenComment:
+			if last == nil {
+				variation.Comment = joinComments(variation.Comment, token.value)
+			} else {
+				last.Comment = joinComments(last.Comment, token.value)
+			}
+		case tokenNAG:
+			nag, err := strconv.Atoi(token.value)
+			if err != nil || nag > maxNAG {
+				return fail("invalid numeric annotation glyph $%s", token.value)
+			}
+			if last == nil {
+				return fail("annotation before the first move")
+			}
+			last.NAGs = append(last.NAGs, nag)
+		case tokenOpenParen:
+			if last == nil {
+				return fail("variation before the first move")
+			}
+			alternative, _, err := reader.readVariation(before, true)
+			if err != nil {
+				return Variation{}, chess.Ongoing, err
+			}
+			last.Variations = append(last.Variations, alternative)
+		case tokenCloseParen:
+			if !nested {
+				return fail("unexpected )")
+			}
+			return variation, chess.Ongoing, nil
+		case tokenAsterisk:
+			if nested {
+				return fail("game termination marker inside a variation")
+			}
+			return variation, chess.Ongoing, nil
+		case tokenSymbol:
+			if result, ok := parseResult(token.value); ok {
+				if nested {
+					return fail("game termination marker inside a variation")
+				}
+				return variation, result, nil
+			}
+			if isMoveNumber(token.value) {
+				continue
+			}
+
+			move, err := chess.AlgebraicNotation(token.value).ToMove(state)
+			if err != nil {
+				return Variation{}, chess.Ongoing, &ParseError{token.line, token.column, err.Error(), err}
+			}
+			before = state
+			state.DoMove(move)
+			variation.Moves = append(variation.Moves, Move{Move: move})
+		case tokenOpenBracket:
+			// The tags of the next game are left to be read once the error is reported
+			reader.scanner.unread(token)
+			return fail("missing game termination marker")
+		default:
+			return fail("unexpected %s", token.value)
+		}
+	}
+}
+
+// parseResult returns the result given by a game termination marker other than *.
+func parseResult(marker string) (chess.Result, bool) {
+	switch marker {
+	case "1-0":
+		return chess.WhiteWins, true
+	case "0-1":
+		return chess.BlackWins, true
+	case "1/2-1/2":
+		return chess.Draw, true
+	}
+	return chess.Ongoing, false
+}
+
+// isMoveNumber returns whether the symbol is made up only of digits.
+func isMoveNumber(symbol string) bool {
+	for _, char := range symbol {
+		if !isDigit(char) {
+			return false
+		}
+	}
+	return true
+}
+
+// joinComments adds a comment to those already made at the same point, separated by a space.
+func joinComments(comments, comment string) string {
+	if comments == "" {
+		return comment
+	}
+	return comments + " " + comment
+}
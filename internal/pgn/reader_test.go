@@ -0,0 +1,207 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+const annotatedPGN = `[Event "Casual game"]
+[Site "London"]
+[Date "1851.06.21"]
+[Round "?"]
+[White "Anderssen, Adolf"]
+[Black "Kieseritzky, Lionel"]
+[Result "1-0"]
+[ECO "C33"]
+
+{The Immortal Game} 1. e4 e5 2. f4 exf4 3. Bc4 Qh4+ 4. Kf1 b5?! (4... d6 {is more solid} (4... Nf6)) 5. Bxb5 Nf6
+6. Nf3 Qh6 7. d3 Nh5 8. Nh4 Qg5 9. Nf5 c6 10. g4 Nf6 11. Rg1 $1 cxb5 12. h4 Qg6 13. h5 Qg5
+; Anderssen sacrifices everything
+14. Qf3 Ng8 15. Bxf4 Qf6 16. Nc3 Bc5 17. Nd5 Qxb2 18. Bd6 Bxg1 19. e5 Qxa1+ 20. Ke2 Na6
+21. Nxg7+ Kd8 22. Qf6+ Nxf6 23. Be7# 1-0
+
+[Event "Second game"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1. e4 Kd7 2. e5 *
+`
+
+func TestReadGames(t *testing.T) {
+	games, err := ReadAll(strings.NewReader(annotatedPGN))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(games) != 2 {
+		t.Fatalf("incorrect number of games: %d", len(games))
+	}
+
+	game := games[0]
+	if len(game.Tags) != 8 {
+		t.Errorf("incorrect number of tags: %d", len(game.Tags))
+	}
+	for _, name := range SevenTagRoster {
+		if _, ok := game.Tag(name); !ok {
+			t.Errorf("missing %s tag", name)
+		}
+	}
+	if white, _ := game.Tag("White"); white != "Anderssen, Adolf" {
+		t.Errorf("incorrect White tag: %s", white)
+	}
+	if game.Result != chess.WhiteWins || len(game.MainLine.Moves) != 45 {
+		t.Errorf("incorrect result or number of moves: %s, %d", game.Result, len(game.MainLine.Moves))
+	}
+	if game.MainLine.Comment != "The Immortal Game" {
+		t.Errorf("incorrect comment before the first move: %q", game.MainLine.Comment)
+	}
+
+	// 4... b5?! with a nested variation
+	move := game.MainLine.Moves[7]
+	if len(move.NAGs) != 1 || move.NAGs[0] != 6 {
+		t.Errorf("incorrect annotation for b5?!: %v", move.NAGs)
+	}
+	if len(move.Variations) != 1 || len(move.Variations[0].Moves) != 1 {
+		t.Fatalf("incorrect variations for b5: %+v", move.Variations)
+	}
+	alternative := move.Variations[0].Moves[0]
+	if alternative.Move.String() != "d7d6" || alternative.Comment != "is more solid" {
+		t.Errorf("incorrect variation move: %s {%s}", alternative.Move, alternative.Comment)
+	}
+	if len(alternative.Variations) != 1 || alternative.Variations[0].Moves[0].Move.String() != "g8f6" {
+		t.Errorf("incorrect nested variation: %+v", alternative.Variations)
+	}
+
+	if nags := game.MainLine.Moves[20].NAGs; len(nags) != 1 || nags[0] != 1 {
+		t.Errorf("incorrect annotation for Rg1 $1: %v", nags)
+	}
+	if comment := game.MainLine.Moves[25].Comment; comment != "Anderssen sacrifices everything" {
+		t.Errorf("incorrect semicolon comment: %q", comment)
+	}
+
+	chessGame, err := game.Replay()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if chessGame.Outcome != (chess.Outcome{Result: chess.WhiteWins, Termination: chess.Checkmate}) {
+		t.Errorf("incorrect outcome after replaying the game: %s", chessGame.Outcome)
+	}
+
+	game = games[1]
+	if game.Start.FEN() != "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1" || game.Result != chess.Ongoing || len(game.MainLine.Moves) != 3 {
+		t.Errorf("incorrect game from a set up position: %s, %s, %d", game.Start.FEN(), game.Result, len(game.MainLine.Moves))
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		pgn    string
+		line   int
+		column int
+	}{
+		{"[Event \"Unterminated]\n\n1. e4 *", 1, 8},
+		{"[Event \"Game\"]\n\n1. e4 e5 2. Ke3 *", 3, 13},
+		{"[Event \"Game\"]\n\n1. e4 {unterminated", 3, 7},
+		{"1. e4 e5 (1... d5", 1, 18},
+		{"1. e4 e5 2. Nf3", 1, 16},
+		{"1. e4 ) *", 1, 7},
+		{"$1 1. e4 *", 1, 1},
+		{"[SetUp \"1\"]\n1. e4 *", 1, 8},
+		{"[FEN \"8/8/8/8 w - - 0 1\"]\n*", 1, 6},
+		{"[Variant \"Bughouse\"]\n*", 1, 10},
+		{"1. e4 e5 (1... d5 1-0) *", 1, 19},
+	}
+
+	for _, test := range tests {
+		_, err := NewReader(strings.NewReader(test.pgn)).Read()
+
+		var parseError *ParseError
+		if !errors.As(err, &parseError) {
+			t.Errorf("expected parse error for %q: %v", test.pgn, err)
+			continue
+		}
+		if parseError.Line != test.line || parseError.Column != test.column {
+			t.Errorf("unexpected error position for %q: expected=%d:%d; actual=%d:%d (%s)", test.pgn, test.line, test.column, parseError.Line, parseError.Column, parseError.Message)
+		}
+	}
+
+	_, err := NewReader(strings.NewReader("1. e4 e5 2. Ke3 *")).Read()
+	if !errors.Is(err, chess.ErrIllegalMove) {
+		t.Errorf("expected an illegal move error: %v", err)
+	}
+}
+
+func TestReadVariantGame(t *testing.T) {
+	pgn := "[Variant \"Crazyhouse\"]\n\n1. e4 d5 2. exd5 Qxd5 3. Nc3 Qa5 4. P@e4 1/2-1/2\n\n% escaped line\n"
+	reader := NewReader(strings.NewReader(pgn))
+
+	game, err := reader.Read()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.Start.GetVariant() != chess.Crazyhouse || game.Result != chess.Draw {
+		t.Errorf("incorrect variant or result: %s, %s", game.Start.GetVariant().Name(), game.Result)
+	}
+	if !game.MainLine.Moves[6].Move.IsDrop() {
+		t.Errorf("expected a drop: %s", game.MainLine.Moves[6].Move)
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("expected the end of the file: %v", err)
+	}
+}
+
+func TestReadChess960Game(t *testing.T) {
+	pgn := "[Variant \"Chess960\"]\n[SetUp \"1\"]\n[FEN \"bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1\"]\n\n1. e4 *\n"
+	game, err := NewReader(strings.NewReader(pgn)).Read()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	start, err := chess.InitialiseChess960State(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if game.Start != start {
+		t.Errorf("incorrect starting position for a Chess960 game: %s", game.Start.FEN())
+	}
+}
+
+func TestReadAfterError(t *testing.T) {
+	pgn := `[Event "Illegal move"]
+
+1. e4 e5 2. Ke3 Nc6 *
+
+[Event "No result"]
+
+1. d4 d5
+
+[Event "Good game"]
+
+1. c4 e5 1-0
+`
+	reader := NewReader(strings.NewReader(pgn))
+
+	for _, line := range []int{3, 9} {
+		_, err := reader.Read()
+		var parseError *ParseError
+		if !errors.As(err, &parseError) || parseError.Line != line {
+			t.Errorf("expected a parse error on line %d: %v", line, err)
+		}
+	}
+
+	game, err := reader.Read()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if event, _ := game.Tag("Event"); event != "Good game" || game.Result != chess.WhiteWins || len(game.MainLine.Moves) != 2 {
+		t.Errorf("incorrect game after errors: %s, %s, %d", event, game.Result, len(game.MainLine.Moves))
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("expected the end of the file: %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package pgn
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// tokenKind is the type of a token in PGN text.
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenSymbol is a move, a move number or a result, such as Nf3, 12 or 1-0.
+	tokenSymbol
+	tokenString
+	tokenComment
+	// tokenNAG is a numeric annotation glyph, such as $1, or a suffix annotation, such as !?, converted to its number.
+	tokenNAG
+	tokenPeriod
+	tokenAsterisk
+	tokenOpenBracket
+	tokenCloseBracket
+	tokenOpenParen
+	tokenCloseParen
+)
+
+// token is a token of PGN text along with the 1-based line and column it starts at.
+type token struct {
+	kind   tokenKind
+	value  string
+	line   int
+	column int
+}
+
+// suffixAnnotations are the move suffix annotations and the numeric annotation glyphs they stand for.
+var suffixAnnotations = map[string]string{
+	"!":  "1",
+	"?":  "2",
+	"!!": "3",
+	"??": "4",
+	"!?": "5",
+	"?!": "6",
+}
+
+// scanner splits PGN text into tokens, keeping track of the line and column of each one.
+type scanner struct {
+	reader *bufio.Reader
+	// line and column give the position of the next rune to be read.
+	line, column int
+	// previousColumn is the column before the last rune read, so that it can be unread.
+	previousColumn int
+	// pending holds a token which has been pushed back to be returned again by next.
+	pending *token
+}
+
+func newScanner(reader io.Reader) *scanner {
+	return &scanner{reader: bufio.NewReader(reader), line: 1, column: 1}
+}
+
+// readRune returns the next rune, or false at the end of the input.
+func (scanner *scanner) readRune() (rune, bool, error) {
+	char, _, err := scanner.reader.ReadRune()
+	if err == io.EOF {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	scanner.previousColumn = scanner.column
+	if char == '\n' {
+		scanner.line++
+		scanner.column = 1
+	} else {
+		scanner.column++
+	}
+	return char, true, nil
+}
+
+// unreadRune steps back over the last rune read.
+func (scanner *scanner) unreadRune(char rune) {
+	scanner.reader.UnreadRune()
+	if char == '\n' {
+		scanner.line--
+	}
+	scanner.column = scanner.previousColumn
+}
+
+// unread pushes a token back, so that it is returned by the next call to next.
+func (scanner *scanner) unread(token token) {
+	scanner.pending = &token
+}
+
+// skipToTags skips ahead to the next [ at the start of a line, which starts the tags of the next game.
+func (scanner *scanner) skipToTags() error {
+	if pending := scanner.pending; pending != nil {
+		if pending.kind == tokenOpenBracket && pending.column == 1 {
+			return nil
+		}
+		scanner.pending = nil
+	}
+
+	for {
+		column := scanner.column
+		char, ok, err := scanner.readRune()
+		if err != nil || !ok {
+			return err
+		}
+		if char == '[' && column == 1 {
+			scanner.unreadRune(char)
+			return nil
+		}
+	}
+}
+
+// next returns the next token, skipping whitespace and lines escaped with a % in the first column.
+func (scanner *scanner) next() (token, error) {
+	if scanner.pending != nil {
+		token := *scanner.pending
+		scanner.pending = nil
+		return token, nil
+	}
+
+	for {
+		line, column := scanner.line, scanner.column
+		char, ok, err := scanner.readRune()
+		if err != nil {
+			return token{}, err
+		}
+		if !ok {
+			return token{tokenEOF, "", line, column}, nil
+		}
+
+		fail := func(message string) (token, error) {
+			return token{}, &ParseError{line, column, message, nil}
+		}
+		single := func(kind tokenKind) (token, error) {
+			return token{kind, string(char), line, column}, nil
+		}
+
+		switch {
+		case char == ' ' || char == '\t' || char == '\r' || char == '\n':
+			continue
+		case char == '%' && column == 1:
+			if _, err := scanner.readUntil('\n'); err != nil {
+				return token{}, err
+			}
+		case char == ';':
+			comment, err := scanner.readUntil('\n')
+			if err != nil {
+				return token{}, err
+			}
+			return token{tokenComment, strings.TrimSpace(comment), line, column}, nil
+		case char == '{':
+			comment, err := scanner.readUntil('}')
+			if err != nil {
+				return token{}, err
+			}
+			if !strings.HasSuffix(comment, "}") {
+				return fail("unterminated comment")
+			}
+			return token{tokenComment, strings.Join(strings.Fields(strings.TrimSuffix(comment, "}")), " "), line, column}, nil
+		case char == '"':
+			return scanner.readString(line, column)
+		case char == '$':
+			digits, err := scanner.readWhile(isDigit)
+			if err != nil {
+				return token{}, err
+			}
+			if digits == "" || len(digits) > 3 {
+				return fail("invalid numeric annotation glyph")
+			}
+			return token{tokenNAG, digits, line, column}, nil
+		case char == '!' || char == '?':
+			annotation, err := scanner.readWhile(func(char rune) bool { return char == '!' || char == '?' })
+			if err != nil {
+				return token{}, err
+			}
+			nag, ok := suffixAnnotations[string(char)+annotation]
+			if !ok {
+				return fail("invalid move suffix annotation " + string(char) + annotation)
+			}
+			return token{tokenNAG, nag, line, column}, nil
+		case char == '.':
+			return single(tokenPeriod)
+		case char == '*':
+			return single(tokenAsterisk)
+		case char == '[':
+			return single(tokenOpenBracket)
+		case char == ']':
+			return single(tokenCloseBracket)
+		case char == '(':
+			return single(tokenOpenParen)
+		case char == ')':
+			return single(tokenCloseParen)
+		case isLetter(char) || isDigit(char) || char == '@':
+			rest, err := scanner.readWhile(isSymbolContinuation)
+			if err != nil {
+				return token{}, err
+			}
+			return token{tokenSymbol, string(char) + rest, line, column}, nil
+		default:
+			return fail("unexpected character " + string(char))
+		}
+	}
+}
+
+// readUntil reads up to and including the given rune, or to the end of the input.
+func (scanner *scanner) readUntil(end rune) (string, error) {
+	var builder strings.Builder
+	for {
+		char, ok, err := scanner.readRune()
+		if err != nil || !ok {
+			return builder.String(), err
+		}
+		builder.WriteRune(char)
+		if char == end {
+			return builder.String(), nil
+		}
+	}
+}
+
+// readWhile reads the runes for which the condition holds.
+func (scanner *scanner) readWhile(condition func(rune) bool) (string, error) {
+	var builder strings.Builder
+	for {
+		char, ok, err := scanner.readRune()
+		if err != nil || !ok {
+			return builder.String(), err
+		}
+		if !condition(char) {
+			scanner.unreadRune(char)
+			return builder.String(), nil
+		}
+		builder.WriteRune(char)
+	}
+}
+
+// readString reads a quoted string after its opening quote, where a backslash escapes a quote or another backslash.
+func (scanner *scanner) readString(line, column int) (token, error) {
+	var builder strings.Builder
+	escaped := false
+	for {
+		char, ok, err := scanner.readRune()
+		if err != nil {
+			return token{}, err
+		}
+		if !ok || char == '\n' {
+			return token{}, &ParseError{line, column, "unterminated string", nil}
+		}
+
+		switch {
+		case escaped:
+			builder.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '"':
+			return token{tokenString, builder.String(), line, column}, nil
+		default:
+			builder.WriteRune(char)
+		}
+	}
+}
+
+func isDigit(char rune) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isLetter(char rune) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+// isSymbolContinuation returns whether the rune may appear after the first character of a symbol.
+// Drops such as N@f3 or @e4 and results such as 1/2-1/2 are read as single symbols.
+func isSymbolContinuation(char rune) bool {
+	return isLetter(char) || isDigit(char) || strings.ContainsRune("_+#=:-/@", char)
+}
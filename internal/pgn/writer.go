@@ -0,0 +1,207 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+// maxLineLength is the most characters written on a line of movetext.
+const maxLineLength = 80
+
+// unknownTagValues are the values written for tags of the seven tag roster which are not given.
+var unknownTagValues = map[string]string{
+	"Date": "????.??.??",
+}
+
+// FromGame returns the moves and result of a chess.Game, with the given tags, as a Game which can be written.
+func FromGame(game chess.Game, tags []Tag) Game {
+	mainLine := Variation{Moves: make([]Move, len(game.Moves))}
+	for i, move := range game.Moves {
+		mainLine.Moves[i] = Move{Move: move}
+	}
+
+	return Game{
+		Tags:     tags,
+		Start:    game.InitialState(),
+		MainLine: mainLine,
+		Result:   game.Outcome.Result,
+	}
+}
+
+// Write writes the game in the PGN export format followed by a blank line, so that several games can be written one after another.
+// The tags of the seven tag roster are written first, with ? for any which are not given and the Result tag taken from the game's result.
+// The Variant, SetUp and FEN tags are added if the game is not played under the standard rules from the standard starting position,
+// and always for Chess960, which is recognised by a Variant tag of Chess960 or by its castling kings and rooks.
+func Write(writer io.Writer, game Game) error {
+	var builder strings.Builder
+	for _, tag := range getExportTags(game) {
+		fmt.Fprintf(&builder, "[%s \"%s\"]\n", tag.Name, escapeTagValue(tag.Value))
+	}
+	builder.WriteString("\n")
+
+	tokens, err := getVariationTokens(game.Start, game.MainLine)
+	if err != nil {
+		return err
+	}
+	tokens = append(tokens, game.Result.String())
+	builder.WriteString(wrapTokens(tokens))
+	builder.WriteString("\n\n")
+
+	_, err = io.WriteString(writer, builder.String())
+	return err
+}
+
+// getExportTags returns the tags of the game in the order they are written.
+func getExportTags(game Game) []Tag {
+	tags := []Tag{}
+	for _, name := range SevenTagRoster {
+		value, ok := game.Tag(name)
+		switch {
+		case name == "Result":
+			value = game.Result.String()
+		case !ok:
+			value = "?"
+			if unknown, ok := unknownTagValues[name]; ok {
+				value = unknown
+			}
+		}
+		tags = append(tags, Tag{name, value})
+	}
+
+	isRoster := func(name string) bool {
+		for _, rosterName := range SevenTagRoster {
+			if name == rosterName {
+				return true
+			}
+		}
+		return false
+	}
+	addMissing := func(name, value string) {
+		if _, ok := game.Tag(name); !ok {
+			tags = append(tags, Tag{name, value})
+		}
+	}
+
+	// A Chess960 game is recognised by its tag or by its castling kings and rooks, and always has its starting position written in Shredder-FEN,
+	// as other readers take KQkq to mean a standard game
+	variant := game.Start.GetVariant()
+	tagVariant, _ := game.Tag("Variant")
+	isChess960 := strings.EqualFold(tagVariant, "Chess960") || game.Start.IsChess960()
+	switch {
+	case variant != chess.Standard:
+		addMissing("Variant", variant.Name())
+	case isChess960:
+		addMissing("Variant", "Chess960")
+	}
+
+	standardStart := chess.InitialiseState()
+	standardStart.SetVariant(variant)
+	switch {
+	case isChess960:
+		addMissing("SetUp", "1")
+		addMissing("FEN", game.Start.ShredderFEN())
+	case game.Start.FEN() != standardStart.FEN():
+		addMissing("SetUp", "1")
+		addMissing("FEN", game.Start.FEN())
+	}
+
+	for _, tag := range game.Tags {
+		if !isRoster(tag.Name) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// escapeTagValue escapes the quotes and backslashes in a tag value.
+func escapeTagValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+// getVariationTokens returns the movetext of a variation played from the given state, split into the tokens which lines may be broken between.
+// The moves are written in standard algebraic notation, with the move number given before every white move
+// and before a black move which starts the variation or follows a comment or another variation.
+func getVariationTokens(state chess.State, variation Variation) ([]string, error) {
+	tokens := []string{}
+	if variation.Comment != "" {
+		tokens = append(tokens, getCommentTokens(variation.Comment)...)
+	}
+
+	needsNumber := true
+	for _, move := range variation.Moves {
+		algebraic, err := move.Move.ToAlgebraicNotation(state)
+		if err != nil {
+			return nil, err
+		}
+
+		if state.ActiveColor == chess.White {
+			tokens = append(tokens, fmt.Sprintf("%d.", state.FullMoveNumber))
+		} else if needsNumber {
+			tokens = append(tokens, fmt.Sprintf("%d...", state.FullMoveNumber))
+		}
+		tokens = append(tokens, string(algebraic))
+		needsNumber = false
+
+		for _, nag := range move.NAGs {
+			tokens = append(tokens, fmt.Sprintf("$%d", nag))
+		}
+		if move.Comment != "" {
+			tokens = append(tokens, getCommentTokens(move.Comment)...)
+			needsNumber = true
+		}
+
+		for _, alternative := range move.Variations {
+			alternativeTokens, err := getVariationTokens(state, alternative)
+			if err != nil {
+				return nil, err
+			}
+			if len(alternativeTokens) == 0 {
+				continue
+			}
+			alternativeTokens[0] = "(" + alternativeTokens[0]
+			alternativeTokens[len(alternativeTokens)-1] += ")"
+			tokens = append(tokens, alternativeTokens...)
+			needsNumber = true
+		}
+
+		state.DoMove(move.Move)
+	}
+
+	return tokens, nil
+}
+
+// getCommentTokens returns a comment in braces, split into words so that it can be wrapped.
+// Closing braces cannot be escaped in a comment, so they are removed.
+func getCommentTokens(comment string) []string {
+	words := strings.Fields(strings.ReplaceAll(comment, "}", ""))
+	if len(words) == 0 {
+		return []string{"{}"}
+	}
+
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+	return words
+}
+
+// wrapTokens joins the tokens with spaces, breaking the lines so that none are longer than maxLineLength unless a single token is.
+func wrapTokens(tokens []string) string {
+	var builder strings.Builder
+	lineLength := 0
+	for _, token := range tokens {
+		switch {
+		case lineLength == 0:
+		case lineLength+1+len(token) > maxLineLength:
+			builder.WriteString("\n")
+			lineLength = 0
+		default:
+			builder.WriteString(" ")
+			lineLength++
+		}
+		builder.WriteString(token)
+		lineLength += len(token)
+	}
+	return builder.String()
+}
@@ -0,0 +1,154 @@
+package pgn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BrianJHenry/chess/internal/chess"
+)
+
+func TestWriteGame(t *testing.T) {
+	game := chess.InitialiseGame()
+	for _, algebraic := range []chess.AlgebraicNotation{"f3", "e5", "g4", "Qh4#"} {
+		if err := game.DoAlgebraicMove(algebraic); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	var builder strings.Builder
+	if err := Write(&builder, FromGame(game, []Tag{{"White", "Fool"}, {"Opening", "Barnes Defense"}})); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Fool"]
+[Black "?"]
+[Result "0-1"]
+[Opening "Barnes Defense"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+
+`
+	if builder.String() != expected {
+		t.Errorf("incorrect PGN:\nexpected=\n%s\nactual=\n%s", expected, builder.String())
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	games, err := ReadAll(strings.NewReader(annotatedPGN))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var builder strings.Builder
+	for _, game := range games {
+		if err := Write(&builder, game); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	for _, line := range strings.Split(builder.String(), "\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line longer than %d characters: %s", maxLineLength, line)
+		}
+	}
+	for _, text := range []string{
+		"{The Immortal Game} 1. e4 e5",
+		"4. Kf1 b5 $6 (4... d6 {is more solid} (4... Nf6)) 5. Bxb5",
+		"11. Rg1 $1 cxb5",
+		"13. h5 Qg5 {Anderssen sacrifices everything} 14. Qf3",
+		"23. Be7# 1-0",
+		"[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1\"]",
+	} {
+		if !strings.Contains(strings.ReplaceAll(builder.String(), "\n", " "), strings.ReplaceAll(text, "\n", " ")) {
+			t.Errorf("missing %q in:\n%s", text, builder.String())
+		}
+	}
+
+	written, err := ReadAll(strings.NewReader(builder.String()))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for i := range games {
+		if !reflect.DeepEqual(written[i].MainLine, games[i].MainLine) || written[i].Result != games[i].Result {
+			t.Errorf("game %d did not round trip", i)
+		}
+	}
+}
+
+func TestWriteVariantGame(t *testing.T) {
+	state, err := chess.InitialiseChess960State(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	state.SetVariant(chess.KingOfTheHill)
+	game, err := chess.InitialiseGameFromState(state)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := game.DoAlgebraicMove("e4"); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var builder strings.Builder
+	if err := Write(&builder, FromGame(game, nil)); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	read, err := NewReader(strings.NewReader(builder.String())).Read()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if read.Start != state || len(read.MainLine.Moves) != 1 || read.Result != chess.Ongoing {
+		t.Errorf("incorrect game after writing:\n%s", builder.String())
+	}
+}
+
+func TestWriteChess960Game(t *testing.T) {
+	tests := []struct {
+		positionNumber int
+		tags           []Tag
+		fen            string
+	}{
+		{0, nil, "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1"},
+		// The kings and rooks start on their standard files, so only the tag shows that the game is Chess960
+		{414, []Tag{{"Variant", "Chess960"}}, "rqnnkbbr/pppppppp/8/8/8/8/PPPPPPPP/RQNNKBBR w HAha - 0 1"},
+		{chess.Chess960StandardPosition, []Tag{{"Variant", "Chess960"}}, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1"},
+	}
+
+	for _, test := range tests {
+		state, err := chess.InitialiseChess960State(test.positionNumber)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		game, err := chess.InitialiseGameFromState(state)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if err := game.DoAlgebraicMove("g3"); err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		var builder strings.Builder
+		if err := Write(&builder, FromGame(game, test.tags)); err != nil {
+			t.Fatalf(err.Error())
+		}
+		for _, text := range []string{"[Variant \"Chess960\"]", "[SetUp \"1\"]", "[FEN \"" + test.fen + "\"]"} {
+			if !strings.Contains(builder.String(), text) {
+				t.Errorf("missing %q for position %d in:\n%s", text, test.positionNumber, builder.String())
+			}
+		}
+
+		read, err := NewReader(strings.NewReader(builder.String())).Read()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if read.Start != state || len(read.MainLine.Moves) != 1 {
+			t.Errorf("position %d did not round trip:\n%s", test.positionNumber, builder.String())
+		}
+	}
+}